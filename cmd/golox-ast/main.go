@@ -48,13 +48,23 @@ func main() {
 		defineAST("Expr", []string{
 			"Binary		:	Expr left, Token operator, Expr right",
 			"Grouping	:	Expr expression",
+			"Index		:	Expr object, Token bracket, Expr index",
 			"Literal	:	Object value",
+			"Logical	:	Expr left, Token operator, Expr right",
+			"Range		:	Expr start, Token operator, Expr end",
 			"Unary		:	Token operator, Expr right",
 		}),
 		defineAST("Stmt", []string{
+			"Assert		:	Token keyword, Expr condition, Expr message",
 			"Expression :	Expr expression",
+			"Match		:	Token keyword, Expr subject, []Pattern patterns, []Stmt bodies",
 			"Print		: 	Expr expression",
 		}),
+		defineAST("Pattern", []string{
+			"Literal	:	Token token, Object value",
+			"Range		:	Object start, Token operator, Object end",
+			"Wildcard	:	Token keyword",
+		}),
 	}
 
 	t, err := template.New("golox-ast").Funcs(template.FuncMap{
@@ -3,49 +3,71 @@ package main
 import (
 	"bufio"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 
+	"github.com/doeg/golox/golox/ast"
 	"github.com/doeg/golox/golox/interpreter"
 	"github.com/doeg/golox/golox/parser"
 	"github.com/doeg/golox/golox/scanner"
+	"github.com/doeg/golox/golox/types"
+)
+
+var (
+	disableAsserts     = flag.Bool("disable-asserts", false, "skip assert statements without evaluating them")
+	optionalSemicolons = flag.Bool("optional-semicolons", false, "let newlines end statements (always on in the REPL)")
 )
 
 func main() {
-	switch len(os.Args) {
-	case 1:
+	flag.Parse()
+
+	switch flag.NArg() {
+	case 0:
 		repl()
-	case 2:
-		fromFile(os.Args[1])
+	case 1:
+		fromFile(flag.Arg(0))
 	default:
-		fmt.Println("Usage: golox [filename]")
+		fmt.Println("Usage: golox [-disable-asserts] [-optional-semicolons] [filename]")
 	}
 }
 
-func execute(input []byte) error {
-	s := scanner.New([]byte(input))
+// parse scans, parses and type checks input, writing any errors and
+// warnings to w.
+func parse(w io.Writer, input []byte, opts ...parser.Option) ([]ast.Stmt, error) {
+	s := scanner.New(input)
 	tokens, errs := s.ScanTokens()
 	if len(errs) > 0 {
 		for _, err := range errs {
 			// FIXME this sucks; figure out a better way to process aggregate errors
-			fmt.Println(err)
+			fmt.Fprintln(w, err)
 		}
-		return errors.New("multiple errors")
+		return nil, errors.New("multiple errors")
 
 	}
 
-	p := parser.New(tokens)
-	expr, err := p.Parse()
+	p := parser.New(tokens, opts...)
+	stmts, err := p.Parse()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	c := types.New()
+	errs = c.Check(stmts)
+
+	for _, warning := range c.Warnings() {
+		fmt.Fprintf(w, "[line %d] Warning: %s\n", warning.Line, warning.Message)
 	}
 
-	i := interpreter.New(os.Stdout)
-	if err := i.Interpret(expr); err != nil {
-		return err
+	if len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprint(w, err.Error())
+		}
+		return nil, errors.New("type errors")
 	}
 
-	return nil
+	return stmts, nil
 }
 
 func fromFile(filename string) {
@@ -54,18 +76,44 @@ func fromFile(filename string) {
 		panic(err)
 	}
 
-	if err := execute(input); err != nil {
+	opts := make([]parser.Option, 0)
+	if *optionalSemicolons {
+		opts = append(opts, parser.OptionalSemicolons())
+	}
+
+	stmts, err := parse(os.Stdout, input, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	i := interpreter.New(os.Stdout, interpreterOptions()...)
+	if err := i.Interpret(stmts); err != nil {
 		panic(err)
 	}
 }
 
+func interpreterOptions() []interpreter.Option {
+	opts := make([]interpreter.Option, 0)
+	if *disableAsserts {
+		opts = append(opts, interpreter.DisableAsserts())
+	}
+
+	return opts
+}
+
 func repl() {
+	s := newSession(os.Stdout, interpreterOptions()...)
+	reader := bufio.NewReader(os.Stdin)
+
 	for {
-		reader := bufio.NewReader(os.Stdin)
 		fmt.Print("> ")
-		text, _ := reader.ReadString('\n')
+		text, err := reader.ReadString('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && text != "") {
+			fmt.Println()
+			return
+		}
 
-		if err := execute([]byte(text)); err != nil {
+		if err := s.run(text); err != nil {
 			fmt.Println("error: ", err)
 			continue
 		}
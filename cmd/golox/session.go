@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io"
+	"strings"
+
+	"github.com/doeg/golox/golox/ast"
+	"github.com/doeg/golox/golox/interpreter"
+	"github.com/doeg/golox/golox/parser"
+)
+
+// session is the state of a REPL. It keeps one interpreter for the whole
+// session, so that definitions persist from one line to the next, until the
+// user starts afresh with ":reset".
+type session struct {
+	writer      io.Writer
+	opts        []interpreter.Option
+	interpreter *interpreter.Interpreter
+}
+
+func newSession(writer io.Writer, opts ...interpreter.Option) *session {
+	s := &session{
+		writer: writer,
+		opts:   opts,
+	}
+	s.reset()
+
+	return s
+}
+
+// reset replaces the session's interpreter with a fresh one.
+func (s *session) reset() {
+	s.interpreter = interpreter.New(s.writer, s.opts...)
+}
+
+// run executes a single line of input. Since each line is a complete
+// input, the end of the line ends a statement whether or not it has a ';',
+// as if -optional-semicolons were set. Top-level expression statements
+// print their value.
+func (s *session) run(line string) error {
+	switch strings.TrimSpace(line) {
+	case "":
+		return nil
+	case ":reset":
+		s.reset()
+		return nil
+	}
+
+	stmts, err := parse(s.writer, []byte(line), parser.OptionalSemicolons())
+	if err != nil {
+		return err
+	}
+
+	for idx, stmt := range stmts {
+		if exprStmt, ok := stmt.(*ast.ExpressionStmt); ok {
+			stmts[idx] = &ast.PrintStmt{Expression: exprStmt.Expression}
+		}
+	}
+
+	return s.interpreter.Interpret(stmts)
+}
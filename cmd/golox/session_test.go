@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/doeg/golox/golox/interpreter"
+	"github.com/doeg/golox/golox/loxerror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRun(t *testing.T) {
+	tests := []struct {
+		testName      string
+		lines         []string
+		expected      string
+		expectedError error
+	}{
+		{
+			testName: "expression statements print their value",
+			lines:    []string{"1 + 2", "\"a\" ?? \"b\"\n"},
+			expected: "3\na\n",
+		},
+		{
+			testName: "statements may still end in a semicolon",
+			lines:    []string{"print \"a\";\n", "1 + 2;\n", "print 1; 2\n"},
+			expected: "a\n3\n1\n2\n",
+		},
+		{
+			testName: "an open range ends the line",
+			lines:    []string{"1..\n", "\"hello\"[3..]\n"},
+			expected: "1..\nlo\n",
+		},
+		{
+			testName: "blank lines do nothing",
+			lines:    []string{"\n", "  \n"},
+			expected: "",
+		},
+		{
+			testName:      "type errors are reported",
+			lines:         []string{"1 - \"a\"\n"},
+			expected:      "[line 0] Error: operands of '-' must be numbers, got number and string\n",
+			expectedError: errors.New("type errors"),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.testName, func(t *testing.T) {
+			t.Parallel()
+
+			var output bytes.Buffer
+			s := newSession(&output)
+
+			var err error
+			for _, line := range tt.lines {
+				err = s.run(line)
+			}
+
+			assert.Equal(t, tt.expectedError, err)
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
+
+func TestSessionKeepsInterpreter(t *testing.T) {
+	var output bytes.Buffer
+	s := newSession(&output)
+	i := s.interpreter
+
+	require.Nil(t, s.run("print 1;\n"))
+	assert.Same(t, i, s.interpreter)
+
+	require.Nil(t, s.run(":reset\n"))
+	assert.NotSame(t, i, s.interpreter)
+
+	require.Nil(t, s.run("print 2;\n"))
+	assert.Equal(t, "1\n2\n", output.String())
+}
+
+func TestSessionOptions(t *testing.T) {
+	var output bytes.Buffer
+	s := newSession(&output, interpreter.DisableAsserts())
+	require.Nil(t, s.run("assert false\n"))
+
+	// The options still apply after a reset.
+	require.Nil(t, s.run(":reset\n"))
+	require.Nil(t, s.run("assert false\n"))
+
+	s = newSession(&output)
+	err := s.run("assert false\n")
+
+	var runtimeErr *loxerror.RuntimeError
+	require.True(t, errors.As(err, &runtimeErr))
+	assert.Equal(t, loxerror.AssertionError, runtimeErr.Kind)
+}
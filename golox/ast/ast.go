@@ -8,7 +8,10 @@ import (
 type ExprVisitor interface {
 	VisitBinaryExpr(expr *BinaryExpr) (any, error)
 	VisitGroupingExpr(expr *GroupingExpr) (any, error)
+	VisitIndexExpr(expr *IndexExpr) (any, error)
 	VisitLiteralExpr(expr *LiteralExpr) (any, error)
+	VisitLogicalExpr(expr *LogicalExpr) (any, error)
+	VisitRangeExpr(expr *RangeExpr) (any, error)
 	VisitUnaryExpr(expr *UnaryExpr) (any, error)
 }
 
@@ -34,6 +37,16 @@ func (e *GroupingExpr) Accept(v ExprVisitor) (any, error) {
 	return v.VisitGroupingExpr(e)
 }
 
+type IndexExpr struct {
+	Object  Expr
+	Bracket *token.Token
+	Index   Expr
+}
+
+func (e *IndexExpr) Accept(v ExprVisitor) (any, error) {
+	return v.VisitIndexExpr(e)
+}
+
 type LiteralExpr struct {
 	Value interface{}
 }
@@ -42,6 +55,26 @@ func (e *LiteralExpr) Accept(v ExprVisitor) (any, error) {
 	return v.VisitLiteralExpr(e)
 }
 
+type LogicalExpr struct {
+	Left     Expr
+	Operator *token.Token
+	Right    Expr
+}
+
+func (e *LogicalExpr) Accept(v ExprVisitor) (any, error) {
+	return v.VisitLogicalExpr(e)
+}
+
+type RangeExpr struct {
+	Start    Expr
+	Operator *token.Token
+	End      Expr
+}
+
+func (e *RangeExpr) Accept(v ExprVisitor) (any, error) {
+	return v.VisitRangeExpr(e)
+}
+
 type UnaryExpr struct {
 	Operator *token.Token
 	Right    Expr
@@ -52,7 +85,9 @@ func (e *UnaryExpr) Accept(v ExprVisitor) (any, error) {
 }
 
 type StmtVisitor interface {
+	VisitAssertStmt(expr *AssertStmt) (any, error)
 	VisitExpressionStmt(expr *ExpressionStmt) (any, error)
+	VisitMatchStmt(expr *MatchStmt) (any, error)
 	VisitPrintStmt(expr *PrintStmt) (any, error)
 }
 
@@ -60,6 +95,16 @@ type Stmt interface {
 	Accept(StmtVisitor) (any, error)
 }
 
+type AssertStmt struct {
+	Keyword   *token.Token
+	Condition Expr
+	Message   Expr
+}
+
+func (e *AssertStmt) Accept(v StmtVisitor) (any, error) {
+	return v.VisitAssertStmt(e)
+}
+
 type ExpressionStmt struct {
 	Expression Expr
 }
@@ -68,6 +113,17 @@ func (e *ExpressionStmt) Accept(v StmtVisitor) (any, error) {
 	return v.VisitExpressionStmt(e)
 }
 
+type MatchStmt struct {
+	Keyword  *token.Token
+	Subject  Expr
+	Patterns []Pattern
+	Bodies   []Stmt
+}
+
+func (e *MatchStmt) Accept(v StmtVisitor) (any, error) {
+	return v.VisitMatchStmt(e)
+}
+
 type PrintStmt struct {
 	Expression Expr
 }
@@ -75,3 +131,40 @@ type PrintStmt struct {
 func (e *PrintStmt) Accept(v StmtVisitor) (any, error) {
 	return v.VisitPrintStmt(e)
 }
+
+type PatternVisitor interface {
+	VisitLiteralPattern(expr *LiteralPattern) (any, error)
+	VisitRangePattern(expr *RangePattern) (any, error)
+	VisitWildcardPattern(expr *WildcardPattern) (any, error)
+}
+
+type Pattern interface {
+	Accept(PatternVisitor) (any, error)
+}
+
+type LiteralPattern struct {
+	Token *token.Token
+	Value interface{}
+}
+
+func (e *LiteralPattern) Accept(v PatternVisitor) (any, error) {
+	return v.VisitLiteralPattern(e)
+}
+
+type RangePattern struct {
+	Start    interface{}
+	Operator *token.Token
+	End      interface{}
+}
+
+func (e *RangePattern) Accept(v PatternVisitor) (any, error) {
+	return v.VisitRangePattern(e)
+}
+
+type WildcardPattern struct {
+	Keyword *token.Token
+}
+
+func (e *WildcardPattern) Accept(v PatternVisitor) (any, error) {
+	return v.VisitWildcardPattern(e)
+}
@@ -0,0 +1,69 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Print returns Lox source code for the given expression. Whitespace is
+// normalized, so the result may not be identical to the original source,
+// but it parses back into the same tree.
+func Print(expr Expr) string {
+	s, _ := expr.Accept(printer{})
+	return s.(string)
+}
+
+// printer is an ExprVisitor that renders each expression as a string.
+type printer struct{}
+
+func (p printer) VisitBinaryExpr(expr *BinaryExpr) (any, error) {
+	return fmt.Sprintf("%s %s %s", Print(expr.Left), expr.Operator.Lexeme, Print(expr.Right)), nil
+}
+
+func (p printer) VisitGroupingExpr(expr *GroupingExpr) (any, error) {
+	return fmt.Sprintf("(%s)", Print(expr.Expression)), nil
+}
+
+func (p printer) VisitIndexExpr(expr *IndexExpr) (any, error) {
+	return fmt.Sprintf("%s%s%s]", Print(expr.Object), expr.Bracket.Lexeme, Print(expr.Index)), nil
+}
+
+func (p printer) VisitLiteralExpr(expr *LiteralExpr) (any, error) {
+	switch v := expr.Value.(type) {
+	case nil:
+		return "nil", nil
+	case string:
+		return fmt.Sprintf("\"%s\"", v), nil
+	case float64:
+		// Like value.Number's String, but without switching to an exponent
+		// for large numbers, which Lox can't scan.
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+func (p printer) VisitLogicalExpr(expr *LogicalExpr) (any, error) {
+	return fmt.Sprintf("%s %s %s", Print(expr.Left), expr.Operator.Lexeme, Print(expr.Right)), nil
+}
+
+func (p printer) VisitRangeExpr(expr *RangeExpr) (any, error) {
+	var b strings.Builder
+
+	if expr.Start != nil {
+		b.WriteString(Print(expr.Start))
+	}
+
+	b.WriteString(expr.Operator.Lexeme)
+
+	if expr.End != nil {
+		b.WriteString(Print(expr.End))
+	}
+
+	return b.String(), nil
+}
+
+func (p printer) VisitUnaryExpr(expr *UnaryExpr) (any, error) {
+	return expr.Operator.Lexeme + Print(expr.Right), nil
+}
@@ -0,0 +1,60 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/doeg/golox/golox/ast"
+	"github.com/doeg/golox/golox/parser"
+	"github.com/doeg/golox/golox/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "1", expected: "1"},
+		{input: "1.5", expected: "1.5"},
+		{input: "1000000000000000000000", expected: "1000000000000000000000"},
+		{input: "0.0000001", expected: "0.0000001"},
+		{input: "\"hello\"", expected: "\"hello\""},
+		{input: "nil", expected: "nil"},
+		{input: "true", expected: "true"},
+		{input: "-1", expected: "-1"},
+		{input: "!!true", expected: "!!true"},
+		{input: "1+2*3", expected: "1 + 2 * 3"},
+		{input: "( 1 + 2 ) * 3", expected: "(1 + 2) * 3"},
+		{input: "1 == 2", expected: "1 == 2"},
+		{input: "nil ?? \"a\"", expected: "nil ?? \"a\""},
+		{input: "\"hello\"[ 1 .. 3 ]", expected: "\"hello\"[1..3]"},
+		{input: "\"hello\"?[..=-1]", expected: "\"hello\"?[..=-1]"},
+		{input: "\"hello\"[2..]", expected: "\"hello\"[2..]"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			s := scanner.New([]byte(tt.input))
+			tokens, errs := s.ScanTokens()
+			require.Empty(t, errs)
+
+			p := parser.New(tokens)
+			expr, err := p.ParseExpression()
+			require.Nil(t, err)
+
+			assert.Equal(t, tt.expected, ast.Print(expr))
+
+			// The printed source parses back into the same tree.
+			tokens, errs = scanner.New([]byte(ast.Print(expr))).ScanTokens()
+			require.Empty(t, errs)
+
+			reparsed, err := parser.New(tokens).ParseExpression()
+			require.Nil(t, err)
+			assert.Equal(t, expr, reparsed)
+		})
+	}
+}
@@ -4,21 +4,68 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 
 	"github.com/doeg/golox/golox/ast"
+	"github.com/doeg/golox/golox/loxerror"
 	"github.com/doeg/golox/golox/token"
+	"github.com/doeg/golox/golox/value"
 )
 
+// DefaultMaxDepth is the maximum evaluation depth of an Interpreter
+// unless configured otherwise with MaxDepth.
+const DefaultMaxDepth = 10000
+
 type Interpreter struct {
 	writer io.Writer
+
+	// disableAsserts skips assert statements entirely,
+	// without evaluating their conditions.
+	disableAsserts bool
+
+	// depth is the number of evaluate and execute calls currently in progress
+	depth int
+
+	// maxDepth is the limit for depth, or 0 for no limit
+	maxDepth int
 }
 
-func New(writer io.Writer) *Interpreter {
-	return &Interpreter{
-		writer: writer,
+// Option configures an Interpreter.
+type Option func(*Interpreter)
+
+// DisableAsserts makes the interpreter skip assert statements.
+func DisableAsserts() Option {
+	return func(i *Interpreter) {
+		i.disableAsserts = true
 	}
 }
 
+// MaxDepth limits how deeply evaluation may nest before the interpreter
+// returns a StackOverflowError, rather than letting deeply nested programs
+// overflow the Go stack and crash the host process. A limit of 0 or less
+// disables the check.
+func MaxDepth(n int) Option {
+	return func(i *Interpreter) {
+		if n < 0 {
+			n = 0
+		}
+		i.maxDepth = n
+	}
+}
+
+func New(writer io.Writer, opts ...Option) *Interpreter {
+	i := &Interpreter{
+		maxDepth: DefaultMaxDepth,
+		writer:   writer,
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return i
+}
+
 func (i *Interpreter) Interpret(statements []ast.Stmt) error {
 	for _, stmt := range statements {
 		if _, err := i.execute(stmt); err != nil {
@@ -29,6 +76,67 @@ func (i *Interpreter) Interpret(statements []ast.Stmt) error {
 	return nil
 }
 
+// VisitAssertStmt returns an error if the assertion's condition is falsey.
+// The error names the failing condition and, for comparisons, the values
+// of both operands.
+func (i *Interpreter) VisitAssertStmt(stmt *ast.AssertStmt) (any, error) {
+	if i.disableAsserts {
+		return nil, nil
+	}
+
+	var result value.Value
+	var operands string
+
+	if expr, ok := stmt.Condition.(*ast.BinaryExpr); ok && isComparison(expr.Operator) {
+		// Evaluate the operands separately so that they can be reported,
+		// taking care not to evaluate either of them twice.
+		left, err := i.evaluate(expr.Left)
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := i.evaluate(expr.Right)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err = i.applyBinary(expr.Operator, left, right)
+		if err != nil {
+			return nil, err
+		}
+
+		operands = fmt.Sprintf("left: %s, right: %s", describe(left), describe(right))
+	} else {
+		val, err := i.evaluate(stmt.Condition)
+		if err != nil {
+			return nil, err
+		}
+
+		result = val
+	}
+
+	if value.Truthy(result) {
+		return nil, nil
+	}
+
+	message := ast.Print(stmt.Condition)
+
+	if stmt.Message != nil {
+		val, err := i.evaluate(stmt.Message)
+		if err != nil {
+			return nil, err
+		}
+
+		message = fmt.Sprintf("%s: %s", message, val)
+	}
+
+	if operands != "" {
+		message = fmt.Sprintf("%s (%s)", message, operands)
+	}
+
+	return nil, runtimeError(stmt.Keyword, loxerror.AssertionError, "%s", message)
+}
+
 func (i *Interpreter) VisitBinaryExpr(expr *ast.BinaryExpr) (any, error) {
 	left, err := i.evaluate(expr.Left)
 	if err != nil {
@@ -40,48 +148,175 @@ func (i *Interpreter) VisitBinaryExpr(expr *ast.BinaryExpr) (any, error) {
 		return nil, err
 	}
 
+	return i.applyBinary(expr.Operator, left, right)
+}
+
+func (i *Interpreter) VisitExpressionStmt(stmt *ast.ExpressionStmt) (any, error) {
+	return i.evaluate(stmt.Expression)
+}
+
+func (i *Interpreter) VisitGroupingExpr(expr *ast.GroupingExpr) (any, error) {
+	return i.evaluate(expr.Expression)
+}
+
+// VisitIndexExpr indexes or slices a string. A number index selects a single
+// character, counting back from the end if negative; a range index selects a
+// substring as described by value.Range.Bounds.
+func (i *Interpreter) VisitIndexExpr(expr *ast.IndexExpr) (any, error) {
+	result, _, err := i.evaluateIndex(expr)
+	return result, err
+}
+
+func (i *Interpreter) VisitLiteralExpr(expr *ast.LiteralExpr) (any, error) {
+	return value.Of(expr.Value)
+}
+
+func (i *Interpreter) VisitMatchStmt(stmt *ast.MatchStmt) (any, error) {
+	subject, err := i.evaluate(stmt.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, pattern := range stmt.Patterns {
+		isMatch, err := i.matchPattern(pattern, subject)
+		if err != nil {
+			return nil, err
+		}
+
+		if isMatch {
+			return i.execute(stmt.Bodies[idx])
+		}
+	}
+
+	return nil, nil
+}
+
+func (i *Interpreter) VisitLogicalExpr(expr *ast.LogicalExpr) (any, error) {
+	left, err := i.evaluate(expr.Left)
+	if err != nil {
+		return nil, err
+	}
+
 	switch expr.Operator.Type {
-	case token.BANG_EQUAL:
-		eq, err := i.isEqual(left, right)
+	case token.QUESTION_QUESTION:
+		if _, isNil := left.(value.Nil); !isNil {
+			return left, nil
+		}
+		return i.evaluate(expr.Right)
+	}
+
+	return nil, errors.New("invalid logical operator")
+}
+
+func (i *Interpreter) VisitPrintStmt(stmt *ast.PrintStmt) (any, error) {
+	val, err := i.evaluate(stmt.Expression)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := i.writer.Write([]byte(val.String() + "\n")); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (i *Interpreter) VisitRangeExpr(expr *ast.RangeExpr) (any, error) {
+	r := value.Range{
+		Inclusive: expr.Operator.Type == token.DOT_DOT_EQUAL,
+	}
+
+	if expr.Start != nil {
+		start, err := i.evaluate(expr.Start)
+		if err != nil {
+			return nil, err
+		}
+
+		r.Start, err = i.checkIntegerOperand(expr.Operator, start, "range bound")
 		if err != nil {
-			return eq, err
+			return nil, err
 		}
-		return !eq, err
+		r.HasStart = true
+	}
+
+	if expr.End != nil {
+		end, err := i.evaluate(expr.End)
+		if err != nil {
+			return nil, err
+		}
+
+		r.End, err = i.checkIntegerOperand(expr.Operator, end, "range bound")
+		if err != nil {
+			return nil, err
+		}
+		r.HasEnd = true
+	}
+
+	return r, nil
+}
+
+func (i *Interpreter) VisitUnaryExpr(expr *ast.UnaryExpr) (any, error) {
+	// Unary expressions have a single sub-expression that we evaluate first.
+	right, err := i.evaluate(expr.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch expr.Operator.Type {
+	case token.MINUS:
+		n, ok := right.(value.Number)
+		if !ok {
+			return nil, runtimeError(expr.Operator, loxerror.TypeError, "operand of '-' must be a number, got %s", right.TypeName())
+		}
+		return -n, nil
+	case token.BANG:
+		return value.Bool(!value.Truthy(right)), nil
+	}
+
+	// Unreachable. TODO: return an error...?
+	return value.Nil{}, nil
+}
+
+// applyBinary applies a binary operator to its already-evaluated operands.
+func (i *Interpreter) applyBinary(operator *token.Token, left, right value.Value) (value.Value, error) {
+	switch operator.Type {
+	case token.BANG_EQUAL:
+		return value.Bool(!value.Equal(left, right)), nil
 	case token.EQUAL_EQUAL:
-		return i.isEqual(left, right)
+		return value.Bool(value.Equal(left, right)), nil
 	case token.GREATER:
-		li, ri, err := i.checkNumberOperands(left, right)
+		li, ri, err := i.checkNumberOperands(operator, left, right)
 		if err != nil {
 			return nil, err
 		}
-		return li > ri, err
+		return value.Bool(li > ri), err
 	case token.GREATER_EQUAL:
-		li, ri, err := i.checkNumberOperands(left, right)
+		li, ri, err := i.checkNumberOperands(operator, left, right)
 		if err != nil {
 			return nil, err
 		}
-		return li >= ri, err
+		return value.Bool(li >= ri), err
 	case token.LESS:
-		li, ri, err := i.checkNumberOperands(left, right)
+		li, ri, err := i.checkNumberOperands(operator, left, right)
 		if err != nil {
 			return nil, err
 		}
-		return li < ri, err
+		return value.Bool(li < ri), err
 	case token.LESS_EQUAL:
-		li, ri, err := i.checkNumberOperands(left, right)
+		li, ri, err := i.checkNumberOperands(operator, left, right)
 		if err != nil {
 			return nil, err
 		}
-		return li <= ri, err
+		return value.Bool(li <= ri), err
 	case token.MINUS:
-		li, ri, err := i.checkNumberOperands(left, right)
+		li, ri, err := i.checkNumberOperands(operator, left, right)
 		if err != nil {
 			return nil, err
 		}
 		return li - ri, err
 	case token.PLUS:
 		// Note, here we check for err == nil, NOT != nil
-		li, ri, err := i.checkNumberOperands(left, right)
+		li, ri, err := i.checkNumberOperands(operator, left, right)
 		if err == nil {
 			return li + ri, nil
 		}
@@ -91,15 +326,15 @@ func (i *Interpreter) VisitBinaryExpr(expr *ast.BinaryExpr) (any, error) {
 			return ls + rs, nil
 		}
 
-		return nil, errors.New("operators must be strings or numbers")
+		return nil, runtimeError(operator, loxerror.TypeError, "operands of '+' must be two numbers or two strings, got %s and %s", left.TypeName(), right.TypeName())
 	case token.SLASH:
-		li, ri, err := i.checkNumberOperands(left, right)
+		li, ri, err := i.checkNumberOperands(operator, left, right)
 		if err != nil {
 			return nil, err
 		}
 		return li / ri, err
 	case token.STAR:
-		li, ri, err := i.checkNumberOperands(left, right)
+		li, ri, err := i.checkNumberOperands(operator, left, right)
 		if err != nil {
 			return nil, err
 		}
@@ -109,104 +344,210 @@ func (i *Interpreter) VisitBinaryExpr(expr *ast.BinaryExpr) (any, error) {
 	return nil, errors.New("invalid binary operator")
 }
 
-func (i *Interpreter) VisitExpressionStmt(stmt *ast.ExpressionStmt) (any, error) {
-	return i.evaluate(stmt.Expression)
+// checkIntegerOperand is used for range bounds and indices, which must be
+// whole numbers. The operand is described as 'name' in error messages.
+func (i *Interpreter) checkIntegerOperand(operator *token.Token, operand value.Value, name string) (float64, error) {
+	n, ok := operand.(value.Number)
+	if !ok {
+		return 0, runtimeError(operator, loxerror.TypeError, "%s must be a number, got %s", name, operand.TypeName())
+	}
+
+	if float64(n) != math.Trunc(float64(n)) {
+		return 0, runtimeError(operator, loxerror.ValueError, "%s must be an integer, got %s", name, n)
+	}
+
+	return float64(n), nil
 }
 
-func (i *Interpreter) VisitGroupingExpr(expr *ast.GroupingExpr) (any, error) {
-	return i.evaluate(expr.Expression)
+func (i *Interpreter) checkNumberOperands(operator *token.Token, left, right value.Value) (value.Number, value.Number, error) {
+	li, lok := left.(value.Number)
+	ri, rok := right.(value.Number)
+
+	if !lok || !rok {
+		return li, ri, runtimeError(operator, loxerror.TypeError, "operands of '%s' must be numbers, got %s and %s", operator.Lexeme, left.TypeName(), right.TypeName())
+	}
+
+	return li, ri, nil
 }
 
-func (i *Interpreter) VisitLiteralExpr(expr *ast.LiteralExpr) (any, error) {
-	return expr.Value, nil
+func (i *Interpreter) checkStringOperands(left, right value.Value) (value.String, value.String, error) {
+	li, lok := left.(value.String)
+	ri, rok := right.(value.String)
+
+	if !lok || !rok {
+		// TODO a better error message
+		return li, ri, errors.New("operands must be strings")
+	}
+
+	return li, ri, nil
 }
 
-func (i *Interpreter) VisitPrintStmt(stmt *ast.PrintStmt) (any, error) {
-	expr, err := i.evaluate(stmt.Expression)
-	if err != nil {
-		return nil, err
+// enter increments the evaluation depth, returning an error if that
+// exceeds the maximum depth. Every call to enter must be paired with leave.
+func (i *Interpreter) enter() error {
+	i.depth++
+
+	if i.maxDepth > 0 && i.depth > i.maxDepth {
+		return runtimeError(nil, loxerror.StackOverflowError, "stack overflow: maximum depth of %d exceeded", i.maxDepth)
 	}
 
-	if _, err := i.writer.Write([]byte(fmt.Sprintf("%+v\n", expr))); err != nil {
+	return nil
+}
+
+func (i *Interpreter) execute(stmt ast.Stmt) (any, error) {
+	defer i.leave()
+	if err := i.enter(); err != nil {
 		return nil, err
 	}
 
-	return nil, nil
+	return stmt.Accept(i)
 }
 
-func (i *Interpreter) VisitUnaryExpr(expr *ast.UnaryExpr) (any, error) {
-	// Unary expressions have a single sub-expression that we evaluate first.
-	right, err := i.evaluate(expr.Right)
+func (i *Interpreter) evaluate(expr ast.Expr) (value.Value, error) {
+	defer i.leave()
+	if err := i.enter(); err != nil {
+		return nil, err
+	}
+
+	result, err := expr.Accept(i)
 	if err != nil {
 		return nil, err
 	}
 
-	switch expr.Operator.Type {
-	case token.MINUS:
-		f, ok := right.(float64)
-		if !ok {
-			// TODO handle this more tidily (as a runtime error)
-			return nil, errors.New("invalid cast")
+	return result.(value.Value), nil
+}
+
+// evaluateIndex evaluates an index expression and the chain of index
+// expressions it is part of. When a null-safe `?[` link finds a nil object,
+// the rest of the chain is skipped, so `s?[0][1]` is nil rather than an error
+// when s is nil. The returned bool is true if the chain was short-circuited.
+func (i *Interpreter) evaluateIndex(expr *ast.IndexExpr) (value.Value, bool, error) {
+	// Chained index expressions recurse here rather than through evaluate,
+	// so they have to count towards the depth limit themselves.
+	defer i.leave()
+	if err := i.enter(); err != nil {
+		return nil, false, err
+	}
+
+	var object value.Value
+
+	if inner, ok := expr.Object.(*ast.IndexExpr); ok {
+		val, isShort, err := i.evaluateIndex(inner)
+		if err != nil || isShort {
+			return val, isShort, err
 		}
-		return -f, nil
-	case token.BANG:
-		return !i.isTruthy(right), nil
+		object = val
+	} else {
+		val, err := i.evaluate(expr.Object)
+		if err != nil {
+			return nil, false, err
+		}
+		object = val
 	}
 
-	// Unreachable. TODO: return an error...?
-	return nil, nil
-}
+	if _, isNil := object.(value.Nil); isNil && expr.Bracket.Type == token.QUESTION_LEFT_BRACKET {
+		return value.Nil{}, true, nil
+	}
 
-func (i *Interpreter) checkNumberOperands(left, right any) (float64, float64, error) {
-	li, lok := left.(float64)
-	ri, rok := right.(float64)
+	index, err := i.evaluate(expr.Index)
+	if err != nil {
+		return nil, false, err
+	}
 
-	if !lok || !rok {
-		// TODO a better error message
-		return li, ri, errors.New("operands must be numbers")
+	str, ok := object.(value.String)
+	if !ok {
+		return nil, false, runtimeError(expr.Bracket, loxerror.TypeError, "only strings can be indexed, got %s", object.TypeName())
 	}
 
-	return li, ri, nil
-}
+	runes := []rune(str)
+
+	switch idx := index.(type) {
+	case value.Number:
+		n, err := i.checkIntegerOperand(expr.Bracket, idx, "string index")
+		if err != nil {
+			return nil, false, err
+		}
 
-func (i *Interpreter) checkStringOperands(left, right any) (string, string, error) {
-	li, lok := left.(string)
-	ri, rok := right.(string)
+		if n < 0 {
+			n += float64(len(runes))
+		}
 
-	if !lok || !rok {
-		// TODO a better error message
-		return li, ri, errors.New("operands must be strings")
+		if n < 0 || n >= float64(len(runes)) {
+			return nil, false, runtimeError(expr.Bracket, loxerror.IndexError, "string index %s out of range for length %d", idx, len(runes))
+		}
+
+		return value.String(runes[int(n)]), false, nil
+	case value.Range:
+		start, end := idx.Bounds(len(runes))
+		return value.String(runes[start:end]), false, nil
 	}
 
-	return li, ri, nil
+	return nil, false, runtimeError(expr.Bracket, loxerror.TypeError, "string index must be a number or a range, got %s", index.TypeName())
 }
 
-func (i *Interpreter) execute(stmt ast.Stmt) (any, error) {
-	return stmt.Accept(i)
+func (i *Interpreter) leave() {
+	i.depth--
 }
 
-func (i *Interpreter) evaluate(expr ast.Expr) (any, error) {
-	return expr.Accept(i)
+// matchPattern returns true if the given subject satisfies the pattern.
+func (i *Interpreter) matchPattern(pattern ast.Pattern, subject value.Value) (bool, error) {
+	switch p := pattern.(type) {
+	case *ast.LiteralPattern:
+		val, err := value.Of(p.Value)
+		if err != nil {
+			return false, err
+		}
+		return value.Equal(val, subject), nil
+	case *ast.RangePattern:
+		n, ok := subject.(value.Number)
+		if !ok {
+			return false, nil
+		}
+
+		if start, ok := p.Start.(float64); ok && float64(n) < start {
+			return false, nil
+		}
+
+		if end, ok := p.End.(float64); ok {
+			if p.Operator.Type == token.DOT_DOT_EQUAL {
+				return float64(n) <= end, nil
+			}
+			return float64(n) < end, nil
+		}
+
+		return true, nil
+	case *ast.WildcardPattern:
+		return true, nil
+	}
+
+	return false, errors.New("invalid pattern")
 }
 
-func (i *Interpreter) isEqual(a, b any) (bool, error) {
-	// The book uses Java's .equals() method here, which may or
-	// may not have some subtle difference from go's == operator.
-	// Unlike the comparison operators, the book also allows
-	// inequality checks on literals other than numbers.
-	//
-	// I don't really want to dig through all the various implementations
-	// of that method, so I think leaning on == is fine for now.
-	return a == b, nil
+// describe formats a value for error messages, quoting strings
+// so that they can be told apart from other values.
+func describe(val value.Value) string {
+	if s, ok := val.(value.String); ok {
+		return fmt.Sprintf("%q", string(s))
+	}
+
+	return val.String()
 }
 
-func (i *Interpreter) isTruthy(val any) bool {
-	switch v := val.(type) {
-	case nil:
-		return false
-	case bool:
-		return v
-	default:
+// isComparison returns true for operators that compare their operands.
+func isComparison(operator *token.Token) bool {
+	switch operator.Type {
+	case token.BANG_EQUAL, token.EQUAL_EQUAL, token.GREATER, token.GREATER_EQUAL, token.LESS, token.LESS_EQUAL:
 		return true
 	}
+
+	return false
+}
+
+// runtimeError returns a RuntimeError of the given kind at tok.
+func runtimeError(tok *token.Token, kind loxerror.ErrorKind, format string, args ...any) error {
+	return &loxerror.RuntimeError{
+		Kind:    kind,
+		Message: fmt.Sprintf(format, args...),
+		Token:   tok,
+	}
 }
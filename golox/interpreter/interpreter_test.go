@@ -3,69 +3,19 @@ package interpreter
 import (
 	"bytes"
 	"errors"
-	"fmt"
 	"math"
 	"testing"
 
 	"github.com/doeg/golox/golox/ast"
+	"github.com/doeg/golox/golox/loxerror"
 	"github.com/doeg/golox/golox/parser"
 	"github.com/doeg/golox/golox/scanner"
+	"github.com/doeg/golox/golox/token"
+	"github.com/doeg/golox/golox/value"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestIsTruthy(t *testing.T) {
-	tests := []struct {
-		input    any
-		expected bool
-	}{
-		{
-			input:    false,
-			expected: false,
-		},
-		{
-			input:    nil,
-			expected: false,
-		},
-		{
-			input:    true,
-			expected: true,
-		},
-		{
-			input:    "hello",
-			expected: true,
-		},
-		{
-			input:    0,
-			expected: true,
-		},
-		{
-			input:    1,
-			expected: true,
-		},
-		{
-			input:    -1,
-			expected: true,
-		},
-		{
-			input:    "",
-			expected: true,
-		},
-	}
-
-	for _, tt := range tests {
-		tt := tt
-		t.Run(fmt.Sprintf("%+v", tt.input), func(t *testing.T) {
-			t.Parallel()
-
-			var output bytes.Buffer
-			i := New(&output)
-			result := i.isTruthy(tt.input)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 func TestVisitBinaryExpression(t *testing.T) {
 	tests := []struct {
 		input         string
@@ -77,257 +27,329 @@ func TestVisitBinaryExpression(t *testing.T) {
 		//
 		{
 			input:    "1 != 2",
-			expected: true,
+			expected: value.Bool(true),
 		},
 		{
 			input:    "1 != 2 != 3",
-			expected: true,
+			expected: value.Bool(true),
 		},
 		{
 			input:    "1 != \"hello\"",
-			expected: true,
+			expected: value.Bool(true),
 		},
 		{
 			input:    "\"hello\" != false",
-			expected: true,
+			expected: value.Bool(true),
 		},
 		{
 			input:    "nil != nil",
-			expected: false,
+			expected: value.Bool(false),
 		},
 		//
 		// token.EQUAL_EQUAL
 		//
 		{
 			input:    "nil == nil",
-			expected: true,
+			expected: value.Bool(true),
 		},
 		{
 			input:    "1 == 1",
-			expected: true,
+			expected: value.Bool(true),
 		},
 		{
 			input:    "\"hello\" == \"hello\"",
-			expected: true,
+			expected: value.Bool(true),
 		},
 		{
 			input:    "false == false",
-			expected: true,
+			expected: value.Bool(true),
 		},
 		{
 			input:    "1 == 1 == 1",
-			expected: false,
+			expected: value.Bool(false),
 		},
 		{
 			input:    "1 == 2",
-			expected: false,
+			expected: value.Bool(false),
 		},
 		{
 			input:    "1 == 2 == 3",
-			expected: false,
+			expected: value.Bool(false),
 		},
 		{
 			input:    "1 == \"hello\"",
-			expected: false,
+			expected: value.Bool(false),
 		},
 		{
 			input:    "\"hello\" == false",
-			expected: false,
+			expected: value.Bool(false),
 		},
 		//
 		// token.GREATER
 		//
 		{
 			input:    "2 > 1",
-			expected: true,
+			expected: value.Bool(true),
 		},
 		{
 			input:    "1 > 1",
-			expected: false,
+			expected: value.Bool(false),
 		},
 		{
 			input:    "1 > 2",
-			expected: false,
+			expected: value.Bool(false),
 		},
 		{
-			input:         "1 > \"hello\"",
-			expected:      nil,
-			expectedError: errors.New("operands must be numbers"),
+			input:    "1 > \"hello\"",
+			expected: value.Nil{},
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '>' must be numbers, got number and string",
+				Token:   &token.Token{Lexeme: ">", Line: 0, Type: token.GREATER},
+			},
 		},
 		{
-			input:         "\"hello\" > false",
-			expected:      nil,
-			expectedError: errors.New("operands must be numbers"),
+			input:    "\"hello\" > false",
+			expected: value.Nil{},
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '>' must be numbers, got string and bool",
+				Token:   &token.Token{Lexeme: ">", Line: 0, Type: token.GREATER},
+			},
 		},
 		//
 		// token.GREATER_EQUAL
 		//
 		{
 			input:    "1 >= 1",
-			expected: true,
+			expected: value.Bool(true),
 		}, {
 			input:    "2 >= 1",
-			expected: true,
+			expected: value.Bool(true),
 		},
 		{
 			input:    "1 >= 2",
-			expected: false,
+			expected: value.Bool(false),
 		},
 		{
-			input:         "1 > \"hello\"",
-			expected:      nil,
-			expectedError: errors.New("operands must be numbers"),
+			input:    "1 > \"hello\"",
+			expected: value.Nil{},
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '>' must be numbers, got number and string",
+				Token:   &token.Token{Lexeme: ">", Line: 0, Type: token.GREATER},
+			},
 		},
 		{
-			input:         "\"hello\" > false",
-			expected:      nil,
-			expectedError: errors.New("operands must be numbers"),
+			input:    "\"hello\" > false",
+			expected: value.Nil{},
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '>' must be numbers, got string and bool",
+				Token:   &token.Token{Lexeme: ">", Line: 0, Type: token.GREATER},
+			},
 		},
 		//
 		// token.LESS
 		//
 		{
 			input:    "1 < 2",
-			expected: true,
+			expected: value.Bool(true),
 		},
 		{
 			input:    "2 < 1",
-			expected: false,
+			expected: value.Bool(false),
 		},
 		{
 			input:    "1 < 1",
-			expected: false,
+			expected: value.Bool(false),
 		},
 		{
-			input:         "1 < \"hello\"",
-			expected:      nil,
-			expectedError: errors.New("operands must be numbers"),
+			input:    "1 < \"hello\"",
+			expected: value.Nil{},
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '<' must be numbers, got number and string",
+				Token:   &token.Token{Lexeme: "<", Line: 0, Type: token.LESS},
+			},
 		},
 		{
-			input:         "\"hello\" < false",
-			expected:      nil,
-			expectedError: errors.New("operands must be numbers"),
+			input:    "\"hello\" < false",
+			expected: value.Nil{},
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '<' must be numbers, got string and bool",
+				Token:   &token.Token{Lexeme: "<", Line: 0, Type: token.LESS},
+			},
 		},
 		//
 		// token.LESS_EQUAL
 		//
 		{
 			input:    "1 <= 2",
-			expected: true,
+			expected: value.Bool(true),
 		},
 		{
 			input:    "1 <= 1",
-			expected: true,
+			expected: value.Bool(true),
 		},
 		{
 			input:    "2 <= 1",
-			expected: false,
+			expected: value.Bool(false),
 		},
 		{
-			input:         "1 <= \"hello\"",
-			expected:      nil,
-			expectedError: errors.New("operands must be numbers"),
+			input:    "1 <= \"hello\"",
+			expected: value.Nil{},
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '<=' must be numbers, got number and string",
+				Token:   &token.Token{Lexeme: "<=", Line: 0, Type: token.LESS_EQUAL},
+			},
 		},
 		{
-			input:         "\"hello\" <= false",
-			expected:      nil,
-			expectedError: errors.New("operands must be numbers"),
+			input:    "\"hello\" <= false",
+			expected: value.Nil{},
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '<=' must be numbers, got string and bool",
+				Token:   &token.Token{Lexeme: "<=", Line: 0, Type: token.LESS_EQUAL},
+			},
 		},
 		//
 		// token.MINUS
 		//
 		{
 			input:    "1 - 2",
-			expected: float64(-1),
+			expected: value.Number(-1),
 		},
 		{
 			input:    "1 - 2 - 3",
-			expected: float64(-4),
+			expected: value.Number(-4),
 		},
 		{
-			input:         "1 - \"hello\"",
-			expected:      nil,
-			expectedError: errors.New("operands must be numbers"),
+			input:    "1 - \"hello\"",
+			expected: value.Nil{},
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '-' must be numbers, got number and string",
+				Token:   &token.Token{Lexeme: "-", Line: 0, Type: token.MINUS},
+			},
 		},
 		{
-			input:         "\"hello\" - false",
-			expected:      nil,
-			expectedError: errors.New("operands must be numbers"),
+			input:    "\"hello\" - false",
+			expected: value.Nil{},
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '-' must be numbers, got string and bool",
+				Token:   &token.Token{Lexeme: "-", Line: 0, Type: token.MINUS},
+			},
 		},
 		//
 		// token.PLUS
 		//
 		{
 			input:    "1 + 2",
-			expected: float64(3),
+			expected: value.Number(3),
 		},
 		{
 			input:    "1.2 + 3.4",
-			expected: float64(4.6),
+			expected: value.Number(4.6),
 		},
 		{
 			input:    "0 + 0",
-			expected: float64(0),
+			expected: value.Number(0),
 		},
 		{
 			input:    "\"hello\" + \"world\"",
-			expected: "helloworld",
+			expected: value.String("helloworld"),
 		},
 		{
-			input:         "\"hello\" + 1",
-			expectedError: errors.New("operators must be strings or numbers"),
+			input: "\"hello\" + 1",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '+' must be two numbers or two strings, got string and number",
+				Token:   &token.Token{Lexeme: "+", Line: 0, Type: token.PLUS},
+			},
 		},
 		{
-			input:         "1 + false",
-			expectedError: errors.New("operators must be strings or numbers"),
+			input: "1 + false",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '+' must be two numbers or two strings, got number and bool",
+				Token:   &token.Token{Lexeme: "+", Line: 0, Type: token.PLUS},
+			},
 		},
 		{
-			input:         "\"hello\" + false",
-			expectedError: errors.New("operators must be strings or numbers"),
+			input: "\"hello\" + false",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '+' must be two numbers or two strings, got string and bool",
+				Token:   &token.Token{Lexeme: "+", Line: 0, Type: token.PLUS},
+			},
 		},
 		{
-			input:         "true + false",
-			expectedError: errors.New("operators must be strings or numbers"),
+			input: "true + false",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '+' must be two numbers or two strings, got bool and bool",
+				Token:   &token.Token{Lexeme: "+", Line: 0, Type: token.PLUS},
+			},
 		},
 		//
 		// token.SLASH
 		//
 		{
 			input:    "1 / 2",
-			expected: float64(0.5),
+			expected: value.Number(0.5),
 		},
 		{
 			input:    "1 / 0",
-			expected: math.Inf(1),
+			expected: value.Number(math.Inf(1)),
 		},
 		{
-			input:         "1 / false",
-			expectedError: errors.New("operands must be numbers"),
+			input: "1 / false",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '/' must be numbers, got number and bool",
+				Token:   &token.Token{Lexeme: "/", Line: 0, Type: token.SLASH},
+			},
 		},
 		{
-			input:         "\"hello\" / false",
-			expectedError: errors.New("operands must be numbers"),
+			input: "\"hello\" / false",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '/' must be numbers, got string and bool",
+				Token:   &token.Token{Lexeme: "/", Line: 0, Type: token.SLASH},
+			},
 		},
 		//
 		// token.STAR
 		//
 		{
 			input:    "1 * 2",
-			expected: float64(2),
+			expected: value.Number(2),
 		},
 		{
 			input:    "1 * 2 * 3",
-			expected: float64(6),
+			expected: value.Number(6),
 		},
 		{
-			input:         "1 * \"hello\"",
-			expected:      nil,
-			expectedError: errors.New("operands must be numbers"),
+			input:    "1 * \"hello\"",
+			expected: value.Nil{},
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '*' must be numbers, got number and string",
+				Token:   &token.Token{Lexeme: "*", Line: 0, Type: token.STAR},
+			},
 		},
 		{
-			input:         "\"hello\" * false",
-			expected:      nil,
-			expectedError: errors.New("operands must be numbers"),
+			input:    "\"hello\" * false",
+			expected: value.Nil{},
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '*' must be numbers, got string and bool",
+				Token:   &token.Token{Lexeme: "*", Line: 0, Type: token.STAR},
+			},
 		},
 	}
 
@@ -370,27 +392,31 @@ func TestVisitUnaryExpression(t *testing.T) {
 		//
 		{
 			input:    "!true",
-			expected: false,
+			expected: value.Bool(false),
 		},
 		{
 			input:    "!false",
-			expected: true,
+			expected: value.Bool(true),
 		},
 		//
 		// token.MINUS
 		//
 		{
 			input:    "-1",
-			expected: float64(-1),
+			expected: value.Number(-1),
 		},
 		{
 			input:    "-(-1)",
-			expected: float64(1),
+			expected: value.Number(1),
 		},
 		{
-			input:         "-\"hello\"",
-			expected:      nil,
-			expectedError: errors.New("invalid cast"),
+			input:    "-\"hello\"",
+			expected: value.Nil{},
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operand of '-' must be a number, got string",
+				Token:   &token.Token{Lexeme: "-", Line: 0, Type: token.MINUS},
+			},
 		},
 	}
 
@@ -421,3 +447,492 @@ func TestVisitUnaryExpression(t *testing.T) {
 		})
 	}
 }
+
+func TestVisitMatchStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    "match (1) { case 1 => print \"one\"; case 2 => print \"two\"; }",
+			expected: "one\n",
+		},
+		{
+			input:    "match (1 + 1) { case 1 => print \"one\"; case 2 => print \"two\"; }",
+			expected: "two\n",
+		},
+		{
+			input:    "match (-1) { case -1 => print \"minus one\"; case _ => print \"other\"; }",
+			expected: "minus one\n",
+		},
+		{
+			input:    "match (\"a\" + \"b\") { case \"ab\" => print true; case _ => print false; }",
+			expected: "true\n",
+		},
+		{
+			input:    "match (nil) { case false => print \"false\"; case nil => print \"nil\"; }",
+			expected: "nil\n",
+		},
+		{
+			input:    "match (3) { case 1 => print \"one\"; case _ => print \"other\"; case 3 => print \"three\"; }",
+			expected: "other\n",
+		},
+		{
+			input:    "match (3) { case 1 => print \"one\"; }",
+			expected: "",
+		},
+		{
+			input:    "match (2) { case 0..2 => print \"low\"; case 2..=4 => print \"mid\"; case _ => print \"high\"; }",
+			expected: "mid\n",
+		},
+		{
+			input:    "match (-0.5) { case ..0 => print \"negative\"; case 0.. => print \"positive\"; }",
+			expected: "negative\n",
+		},
+		{
+			input:    "match (\"a\") { case .. => print \"number\"; case _ => print \"other\"; }",
+			expected: "other\n",
+		},
+		{
+			input:    "match (1) { case 1 => match (2) { case 2 => print \"nested\"; } }",
+			expected: "nested\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			s := scanner.New([]byte(tt.input))
+			tokens, errs := s.ScanTokens()
+			require.Empty(t, errs)
+
+			p := parser.New(tokens)
+			stmts, err := p.Parse()
+			require.Nil(t, err)
+
+			var output bytes.Buffer
+			i := New(&output)
+			err = i.Interpret(stmts)
+			require.Nil(t, err)
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
+
+func TestVisitIndexExpression(t *testing.T) {
+	tests := []struct {
+		input         string
+		expected      any
+		expectedError error
+	}{
+		{
+			input:    "\"hello\"[0]",
+			expected: value.String("h"),
+		},
+		{
+			input:    "\"hello\"[-1]",
+			expected: value.String("o"),
+		},
+		{
+			input:    "\"héllo\"[1]",
+			expected: value.String("é"),
+		},
+		{
+			input:    "\"hello\"[1..3]",
+			expected: value.String("el"),
+		},
+		{
+			input:    "\"hello\"[1..=3]",
+			expected: value.String("ell"),
+		},
+		{
+			input:    "\"hello\"[..2]",
+			expected: value.String("he"),
+		},
+		{
+			input:    "\"hello\"[-3..]",
+			expected: value.String("llo"),
+		},
+		{
+			input:    "\"hello\"[..]",
+			expected: value.String("hello"),
+		},
+		{
+			input:    "\"hello\"[3..100]",
+			expected: value.String("lo"),
+		},
+		{
+			input:    "\"hello\"[4..1]",
+			expected: value.String(""),
+		},
+		{
+			input: "\"hello\"[5]",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.IndexError,
+				Message: "string index 5 out of range for length 5",
+				Token:   &token.Token{Lexeme: "[", Line: 0, Type: token.LEFT_BRACKET},
+			},
+		},
+		{
+			input: "\"hello\"[-6]",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.IndexError,
+				Message: "string index -6 out of range for length 5",
+				Token:   &token.Token{Lexeme: "[", Line: 0, Type: token.LEFT_BRACKET},
+			},
+		},
+		{
+			input: "\"hello\"[1.5]",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.ValueError,
+				Message: "string index must be an integer, got 1.5",
+				Token:   &token.Token{Lexeme: "[", Line: 0, Type: token.LEFT_BRACKET},
+			},
+		},
+		{
+			input: "\"hello\"[true]",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "string index must be a number or a range, got bool",
+				Token:   &token.Token{Lexeme: "[", Line: 0, Type: token.LEFT_BRACKET},
+			},
+		},
+		{
+			input: "\"hello\"[1..\"a\"]",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "range bound must be a number, got string",
+				Token:   &token.Token{Lexeme: "..", Line: 0, Type: token.DOT_DOT},
+			},
+		},
+		{
+			input: "1[0]",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "only strings can be indexed, got number",
+				Token:   &token.Token{Lexeme: "[", Line: 0, Type: token.LEFT_BRACKET},
+			},
+		},
+		{
+			input:    "\"hello\"?[0]",
+			expected: value.String("h"),
+		},
+		{
+			input:    "nil?[0]",
+			expected: value.Nil{},
+		},
+		{
+			input:    "nil?[0][1][2..]",
+			expected: value.Nil{},
+		},
+		{
+			input:    "\"hello\"[1..]?[0]",
+			expected: value.String("e"),
+		},
+		{
+			input: "(nil?[0])[1]",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "only strings can be indexed, got nil",
+				Token:   &token.Token{Lexeme: "[", Line: 0, Type: token.LEFT_BRACKET},
+			},
+		},
+		{
+			input: "nil[0]",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "only strings can be indexed, got nil",
+				Token:   &token.Token{Lexeme: "[", Line: 0, Type: token.LEFT_BRACKET},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			s := scanner.New([]byte(tt.input))
+			tokens, errs := s.ScanTokens()
+			require.Empty(t, errs)
+
+			p := parser.New(tokens)
+			pExpr, err := p.ParseExpression()
+			require.Nil(t, err)
+
+			expr := pExpr.(*ast.IndexExpr)
+
+			var output bytes.Buffer
+			i := New(&output)
+			result, err := i.VisitIndexExpr(expr)
+			if tt.expectedError != nil {
+				require.Equal(t, tt.expectedError, err)
+			} else {
+				require.Nil(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestVisitLogicalExpression(t *testing.T) {
+	tests := []struct {
+		input         string
+		expected      any
+		expectedError error
+	}{
+		{
+			input:    "nil ?? 1",
+			expected: value.Number(1),
+		},
+		{
+			input:    "false ?? 1",
+			expected: value.Bool(false),
+		},
+		{
+			input:    "\"a\" ?? 1",
+			expected: value.String("a"),
+		},
+		{
+			input:    "nil ?? nil ?? \"b\"",
+			expected: value.String("b"),
+		},
+		{
+			input:    "nil ?? nil",
+			expected: value.Nil{},
+		},
+		{
+			input:    "nil?[0] ?? \"default\"",
+			expected: value.String("default"),
+		},
+		{
+			// The right-hand side is never evaluated when the left isn't nil
+			input:    "1 ?? -\"a\"",
+			expected: value.Number(1),
+		},
+		{
+			input: "nil ?? -\"a\"",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operand of '-' must be a number, got string",
+				Token:   &token.Token{Lexeme: "-", Line: 0, Type: token.MINUS},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			s := scanner.New([]byte(tt.input))
+			tokens, errs := s.ScanTokens()
+			require.Empty(t, errs)
+
+			p := parser.New(tokens)
+			pExpr, err := p.ParseExpression()
+			require.Nil(t, err)
+
+			expr := pExpr.(*ast.LogicalExpr)
+
+			var output bytes.Buffer
+			i := New(&output)
+			result, err := i.VisitLogicalExpr(expr)
+			if tt.expectedError != nil {
+				require.Equal(t, tt.expectedError, err)
+			} else {
+				require.Nil(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestVisitAssertStmt(t *testing.T) {
+	tests := []struct {
+		input          string
+		disableAsserts bool
+		expected       string
+		expectedError  error
+	}{
+		{
+			input:    "assert true; print \"ok\";",
+			expected: "ok\n",
+		},
+		{
+			input:    "assert 1 + 2 == 3, \"math\"; print \"ok\";",
+			expected: "ok\n",
+		},
+		{
+			input: "assert false;",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.AssertionError,
+				Message: "false",
+				Token:   &token.Token{Lexeme: "assert", Line: 0, Type: token.ASSERT},
+			},
+		},
+		{
+			input: "print 1;\nassert nil ?? false, \"no value\";",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.AssertionError,
+				Message: "nil ?? false: no value",
+				Token:   &token.Token{Lexeme: "assert", Line: 1, NewlineBefore: true, Type: token.ASSERT},
+			},
+		},
+		{
+			input: "assert 1 + 2 == 4;",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.AssertionError,
+				Message: "1 + 2 == 4 (left: 3, right: 4)",
+				Token:   &token.Token{Lexeme: "assert", Line: 0, Type: token.ASSERT},
+			},
+		},
+		{
+			input: "assert \"a\" + \"b\" != \"ab\", \"strings\";",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.AssertionError,
+				Message: "\"a\" + \"b\" != \"ab\": strings (left: \"ab\", right: \"ab\")",
+				Token:   &token.Token{Lexeme: "assert", Line: 0, Type: token.ASSERT},
+			},
+		},
+		{
+			input: "assert (1) > 2 * 3;",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.AssertionError,
+				Message: "(1) > 2 * 3 (left: 1, right: 6)",
+				Token:   &token.Token{Lexeme: "assert", Line: 0, Type: token.ASSERT},
+			},
+		},
+		{
+			input: "assert 1 < \"a\";",
+			expectedError: &loxerror.RuntimeError{
+				Kind:    loxerror.TypeError,
+				Message: "operands of '<' must be numbers, got number and string",
+				Token:   &token.Token{Lexeme: "<", Line: 0, Type: token.LESS},
+			},
+		},
+		{
+			input:          "assert false; assert -\"a\"; print \"ok\";",
+			disableAsserts: true,
+			expected:       "ok\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			s := scanner.New([]byte(tt.input))
+			tokens, errs := s.ScanTokens()
+			require.Empty(t, errs)
+
+			p := parser.New(tokens)
+			stmts, err := p.Parse()
+			require.Nil(t, err)
+
+			opts := make([]Option, 0)
+			if tt.disableAsserts {
+				opts = append(opts, DisableAsserts())
+			}
+
+			var output bytes.Buffer
+			i := New(&output, opts...)
+			err = i.Interpret(stmts)
+			if tt.expectedError != nil {
+				require.Equal(t, tt.expectedError, err)
+			} else {
+				require.Nil(t, err)
+				assert.Equal(t, tt.expected, output.String())
+			}
+		})
+	}
+}
+
+func TestVisitPrintStmt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "print nil;", expected: "nil\n"},
+		{input: "print 3;", expected: "3\n"},
+		{input: "print 1 / 2;", expected: "0.5\n"},
+		{input: "print 1 / 0;", expected: "inf\n"},
+		{input: "print true;", expected: "true\n"},
+		{input: "print \"hello\";", expected: "hello\n"},
+		{input: "print 1..=3;", expected: "1..=3\n"},
+		{input: "print \"hello\"?[1..];", expected: "ello\n"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			s := scanner.New([]byte(tt.input))
+			tokens, errs := s.ScanTokens()
+			require.Empty(t, errs)
+
+			p := parser.New(tokens)
+			stmts, err := p.Parse()
+			require.Nil(t, err)
+
+			var output bytes.Buffer
+			i := New(&output)
+			err = i.Interpret(stmts)
+			require.Nil(t, err)
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
+
+func TestRuntimeError(t *testing.T) {
+	s := scanner.New([]byte("print 1;\nprint 1 - \"a\";"))
+	tokens, errs := s.ScanTokens()
+	require.Empty(t, errs)
+
+	p := parser.New(tokens)
+	stmts, err := p.Parse()
+	require.Nil(t, err)
+
+	var output bytes.Buffer
+	i := New(&output)
+	err = i.Interpret(stmts)
+
+	var runtimeErr *loxerror.RuntimeError
+	require.True(t, errors.As(err, &runtimeErr))
+	assert.Equal(t, loxerror.TypeError, runtimeErr.Kind)
+	assert.Equal(t, 1, runtimeErr.Token.Line)
+	assert.Equal(t, "[line 1] TypeError: operands of '-' must be numbers, got number and string\n", err.Error())
+	assert.Equal(t, "1\n", output.String())
+}
+
+func TestMaxDepth(t *testing.T) {
+	s := scanner.New([]byte("print -(-(-(-(-(-1)))));"))
+	tokens, errs := s.ScanTokens()
+	require.Empty(t, errs)
+
+	p := parser.New(tokens)
+	stmts, err := p.Parse()
+	require.Nil(t, err)
+
+	var output bytes.Buffer
+	i := New(&output, MaxDepth(5))
+	err = i.Interpret(stmts)
+
+	var runtimeErr *loxerror.RuntimeError
+	require.True(t, errors.As(err, &runtimeErr))
+	assert.Equal(t, loxerror.StackOverflowError, runtimeErr.Kind)
+	assert.Equal(t, "StackOverflowError: stack overflow: maximum depth of 5 exceeded\n", err.Error())
+	assert.Empty(t, output.String())
+
+	// The depth unwinds with the error, so the interpreter stays usable.
+	assert.Equal(t, 0, i.depth)
+
+	output.Reset()
+	i = New(&output, MaxDepth(0))
+	require.Nil(t, i.Interpret(stmts))
+	assert.Equal(t, "1\n", output.String())
+}
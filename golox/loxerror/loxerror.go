@@ -1,6 +1,10 @@
 package loxerror
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/doeg/golox/golox/token"
+)
 
 type LoxError struct {
 	Line    int
@@ -15,3 +19,68 @@ var (
 func (e *LoxError) Error() string {
 	return fmt.Sprintf("[line %d] Error: %s\n", e.Line, e.Message)
 }
+
+// ErrorKind classifies a RuntimeError.
+type ErrorKind int
+
+const (
+	// TypeError is raised when an operand has the wrong type,
+	// e.g. `1 + "one"`.
+	TypeError ErrorKind = iota
+
+	// NameError is raised when a name can't be resolved.
+	NameError
+
+	// IndexError is raised when an index is out of range.
+	IndexError
+
+	// ValueError is raised when an operand has the right type but an
+	// invalid value, e.g. a fractional index.
+	ValueError
+
+	// AssertionError is raised when an assert statement fails.
+	AssertionError
+
+	// StackOverflowError is raised when evaluation nests deeper than the
+	// interpreter's maximum depth.
+	StackOverflowError
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case TypeError:
+		return "TypeError"
+	case NameError:
+		return "NameError"
+	case IndexError:
+		return "IndexError"
+	case ValueError:
+		return "ValueError"
+	case AssertionError:
+		return "AssertionError"
+	case StackOverflowError:
+		return "StackOverflowError"
+	default:
+		return "Error"
+	}
+}
+
+// RuntimeError is an error raised while a program is being interpreted.
+// Embedders can inspect it with errors.As.
+type RuntimeError struct {
+	Kind    ErrorKind
+	Message string
+
+	// Token is the token where the error occurred, usually the operator
+	// being evaluated. It is nil for errors that have no single location,
+	// such as a stack overflow.
+	Token *token.Token
+}
+
+func (e *RuntimeError) Error() string {
+	if e.Token == nil {
+		return fmt.Sprintf("%s: %s\n", e.Kind, e.Message)
+	}
+
+	return fmt.Sprintf("[line %d] %s: %s\n", e.Token.Line, e.Kind, e.Message)
+}
@@ -11,8 +11,21 @@ import (
 var (
 	ErrExpectClosingParen = "expect ')' after expression"
 	ErrExpectExpression   = "expect expression"
+	ErrExpectPattern      = "expect pattern"
+	ErrTooDeeplyNested    = "program is nested too deeply"
 )
 
+// maxDepth limits the depth of the syntax tree that the parser builds, so
+// that pathological input such as 100,000 opening parens or a chain of
+// 100,000 additions is rejected with an error instead of overflowing the
+// Go stack, either while parsing or in a later pass that walks the tree.
+//
+// Each nested statement, unary operator, parenthesized expression and
+// operand of a chained binary or index operator counts as one level. An
+// expression on its own may therefore have up to maxDepth-1 nested parens
+// around a literal, or be a chain of up to maxDepth operands.
+const maxDepth = 1000
+
 // Parser implements Lox's grammar rules as a collection of methods.
 // Each method for parsing a grammar rule produces a syntax tree for that
 // rule and returns it to the caller.
@@ -21,13 +34,42 @@ type Parser struct {
 
 	// current points to the next token to be parsed
 	current int
+
+	// depth counts the levels of the syntax tree above the node being parsed
+	depth int
+
+	// optionalSemicolons lets newlines end statements.
+	// See insertSemicolons for the rules.
+	optionalSemicolons bool
+}
+
+// Option configures a Parser.
+type Option func(*Parser)
+
+// OptionalSemicolons makes the parser end a statement at a newline wherever
+// the grammar allows it, instead of requiring a ';'. Explicit semicolons are
+// still accepted.
+func OptionalSemicolons() Option {
+	return func(p *Parser) {
+		p.optionalSemicolons = true
+	}
 }
 
-func New(tokens []*token.Token) *Parser {
-	return &Parser{
+func New(tokens []*token.Token, opts ...Option) *Parser {
+	p := &Parser{
 		current: 0,
 		tokens:  tokens,
 	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if p.optionalSemicolons {
+		p.tokens = insertSemicolons(p.tokens)
+	}
+
+	return p
 }
 
 // Parse parses as many statements as we find until EOF.
@@ -90,6 +132,23 @@ func (p *Parser) check(tokenType token.TokenType) (bool, error) {
 	return nextToken.Type == tokenType, nil
 }
 
+// checkAny returns true if the current token is of any of the given types.
+// Like check, it never consumes the token.
+func (p *Parser) checkAny(tokenTypes ...token.TokenType) (bool, error) {
+	for _, tokenType := range tokenTypes {
+		isMatch, err := p.check(tokenType)
+		if err != nil {
+			return false, err
+		}
+
+		if isMatch {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 // consume checks to see if the next token is of the expected type.
 // If so, it consumes the token. If some other token is there, then we've
 // hit an error.
@@ -111,6 +170,19 @@ func (p *Parser) consume(tokenType token.TokenType, message string) (*token.Toke
 	return tok, nil
 }
 
+// enter increments the parser's nesting depth, returning an error if it
+// exceeds maxDepth. Every call to enter must be paired with leave, or be
+// made by a rule that restores the depth on return with unwind.
+func (p *Parser) enter() error {
+	p.depth++
+
+	if p.depth > maxDepth {
+		return errors.New(ErrTooDeeplyNested)
+	}
+
+	return nil
+}
+
 // get returns a pointer to the Token at the given index.
 func (p *Parser) get(index int) (*token.Token, error) {
 	if index < 0 || index >= len(p.tokens) {
@@ -129,6 +201,10 @@ func (p *Parser) isAtEnd() (bool, error) {
 	return nextToken.Type == token.EOF, nil
 }
 
+func (p *Parser) leave() {
+	p.depth--
+}
+
 // match checks to see if the current token has any of the given types.
 // If so, it consumes the token and returns `true`. Otherwise, it returns `false`
 // and leaves the current token alone.
@@ -147,10 +223,96 @@ func (p *Parser) match(tokenTypes ...token.TokenType) (bool, error) {
 	return false, nil
 }
 
+// parseAssertStatement implements the following grammar rule:
+//
+//	assertStmt -> "assert" expression ( "," expression )? ";" ;
+func (p *Parser) parseAssertStatement() (ast.Stmt, error) {
+	keyword, err := p.previous()
+	if err != nil {
+		return nil, err
+	}
+
+	condition, err := p.ParseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	var message ast.Expr
+
+	hasMessage, err := p.match(token.COMMA)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasMessage {
+		message, err = p.ParseExpression()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := p.consume(token.SEMICOLON, "expect ';' after assertion"); err != nil {
+		return nil, err
+	}
+
+	return &ast.AssertStmt{
+		Keyword:   keyword,
+		Condition: condition,
+		Message:   message,
+	}, nil
+}
+
+// parseCoalesce implements the following grammar rule:
+//
+//	coalesce -> equality ( "??" equality )* ;
+func (p *Parser) parseCoalesce() (ast.Expr, error) {
+	defer p.unwind(p.depth)
+
+	expr, err := p.parseEquality()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		isMatch, err := p.match(token.QUESTION_QUESTION)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isMatch {
+			break
+		}
+
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+
+		operator, err := p.previous()
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := p.parseEquality()
+		if err != nil {
+			return nil, err
+		}
+
+		expr = &ast.LogicalExpr{
+			Left:     expr,
+			Operator: operator,
+			Right:    right,
+		}
+	}
+
+	return expr, nil
+}
+
 // parseComparison implements the following grammar rule:
 //
 //	comparison -> term ( ( ">" | ">=" | "<" | "<=" ) term)* ;
 func (p *Parser) parseComparison() (ast.Expr, error) {
+	defer p.unwind(p.depth)
+
 	expr, err := p.parseTerm()
 	if err != nil {
 		return nil, err
@@ -166,6 +328,10 @@ func (p *Parser) parseComparison() (ast.Expr, error) {
 			break
 		}
 
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+
 		operator, err := p.previous()
 		if err != nil {
 			return nil, err
@@ -195,9 +361,11 @@ func (p *Parser) parseDeclaration() (ast.Stmt, error) {
 
 // parseEquality implements the following grammar rule:
 //
-//	equality -> comparison ( ( "!=" | "==" ) comparison )* ;
+//	equality -> range ( ( "!=" | "==" ) range )* ;
 func (p *Parser) parseEquality() (ast.Expr, error) {
-	expr, err := p.parseComparison()
+	defer p.unwind(p.depth)
+
+	expr, err := p.parseRange()
 	if err != nil {
 		return nil, err
 	}
@@ -212,12 +380,16 @@ func (p *Parser) parseEquality() (ast.Expr, error) {
 			break
 		}
 
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+
 		operator, err := p.previous()
 		if err != nil {
 			return nil, err
 		}
 
-		right, err := p.parseComparison()
+		right, err := p.parseRange()
 		if err != nil {
 			return nil, err
 		}
@@ -234,9 +406,9 @@ func (p *Parser) parseEquality() (ast.Expr, error) {
 
 // ParseExpression implements the following grammar rule:
 //
-//	expression -> equality ;
+//	expression -> coalesce ;
 func (p *Parser) ParseExpression() (ast.Expr, error) {
-	return p.parseEquality()
+	return p.parseCoalesce()
 }
 
 // parseExpressionStatement implements the following grammar rule:
@@ -261,6 +433,8 @@ func (p *Parser) parseExpressionStatement() (ast.Stmt, error) {
 //
 //	factor -> unary ( ( "/" | "*" ) unary )* ;
 func (p *Parser) parseFactor() (ast.Expr, error) {
+	defer p.unwind(p.depth)
+
 	expr, err := p.parseUnary()
 	if err != nil {
 		return nil, err
@@ -276,6 +450,10 @@ func (p *Parser) parseFactor() (ast.Expr, error) {
 			break
 		}
 
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+
 		operator, err := p.previous()
 		if err != nil {
 			return nil, err
@@ -296,6 +474,199 @@ func (p *Parser) parseFactor() (ast.Expr, error) {
 	return expr, nil
 }
 
+// parseIndex implements the following grammar rule:
+//
+//	index -> primary ( ( "[" | "?[" ) expression "]" )* ;
+func (p *Parser) parseIndex() (ast.Expr, error) {
+	defer p.unwind(p.depth)
+
+	expr, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		isMatch, err := p.match(token.LEFT_BRACKET, token.QUESTION_LEFT_BRACKET)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isMatch {
+			break
+		}
+
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+
+		bracket, err := p.previous()
+		if err != nil {
+			return nil, err
+		}
+
+		index, err := p.ParseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := p.consume(token.RIGHT_BRACKET, "expect ']' after index"); err != nil {
+			return nil, err
+		}
+
+		expr = &ast.IndexExpr{
+			Object:  expr,
+			Bracket: bracket,
+			Index:   index,
+		}
+	}
+
+	return expr, nil
+}
+
+// parseMatchStatement implements the following grammar rules:
+//
+//	matchStmt -> "match" "(" expression ")" "{" matchCase* "}" ;
+//	matchCase -> "case" pattern "=>" statement ;
+//
+// A match without a wildcard case is allowed; if no case matches
+// the subject, none of the bodies are executed.
+func (p *Parser) parseMatchStatement() (ast.Stmt, error) {
+	keyword, err := p.previous()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := p.consume(token.LEFT_PAREN, "expect '(' after 'match'"); err != nil {
+		return nil, err
+	}
+
+	subject, err := p.ParseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := p.consume(token.RIGHT_PAREN, "expect ')' after match subject"); err != nil {
+		return nil, err
+	}
+
+	if _, err := p.consume(token.LEFT_BRACE, "expect '{' before match cases"); err != nil {
+		return nil, err
+	}
+
+	patterns := make([]ast.Pattern, 0)
+	bodies := make([]ast.Stmt, 0)
+
+	for {
+		isCase, err := p.match(token.CASE)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isCase {
+			break
+		}
+
+		pattern, err := p.parsePattern()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := p.consume(token.EQUAL_GREATER, "expect '=>' after pattern"); err != nil {
+			return nil, err
+		}
+
+		body, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+
+		patterns = append(patterns, pattern)
+		bodies = append(bodies, body)
+	}
+
+	if _, err := p.consume(token.RIGHT_BRACE, "expect '}' after match cases"); err != nil {
+		return nil, err
+	}
+
+	return &ast.MatchStmt{
+		Keyword:  keyword,
+		Subject:  subject,
+		Patterns: patterns,
+		Bodies:   bodies,
+	}, nil
+}
+
+// parsePattern implements the following grammar rules:
+//
+//	pattern -> "_" | "true" | "false" | "nil" | STRING
+//		     | number ( ( ".." | "..=" ) number? )?
+//		     | ( ".." | "..=" ) number? ;
+//	number  -> "-"? NUMBER ;
+//
+// A range pattern matches any number within its bounds. As with range
+// expressions, either bound may be omitted to leave that side open.
+func (p *Parser) parsePattern() (ast.Pattern, error) {
+	tok, err := p.advance()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok.Type {
+	case token.IDENTIFIER:
+		if tok.Lexeme == "_" {
+			return &ast.WildcardPattern{Keyword: tok}, nil
+		}
+	case token.FALSE:
+		return &ast.LiteralPattern{Token: tok, Value: false}, nil
+	case token.TRUE:
+		return &ast.LiteralPattern{Token: tok, Value: true}, nil
+	case token.NIL:
+		return &ast.LiteralPattern{Token: tok, Value: nil}, nil
+	case token.STRING:
+		return &ast.LiteralPattern{Token: tok, Value: tok.Literal}, nil
+	case token.DOT_DOT, token.DOT_DOT_EQUAL:
+		return p.parseRangePattern(nil, tok)
+	case token.MINUS, token.NUMBER:
+		num, err := p.parsePatternNumber(tok)
+		if err != nil {
+			return nil, err
+		}
+
+		isRange, err := p.match(token.DOT_DOT, token.DOT_DOT_EQUAL)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isRange {
+			return &ast.LiteralPattern{Token: tok, Value: num}, nil
+		}
+
+		operator, err := p.previous()
+		if err != nil {
+			return nil, err
+		}
+
+		return p.parseRangePattern(num, operator)
+	}
+
+	return nil, errors.New(ErrExpectPattern)
+}
+
+// parsePatternNumber parses the number in a pattern, starting from tok,
+// which is either a NUMBER or the "-" that negates it.
+func (p *Parser) parsePatternNumber(tok *token.Token) (float64, error) {
+	if tok.Type == token.MINUS {
+		num, err := p.consume(token.NUMBER, ErrExpectPattern)
+		if err != nil {
+			return 0, err
+		}
+
+		return -num.Literal.(float64), nil
+	}
+
+	return tok.Literal.(float64), nil
+}
+
 // parsePrimary implements the following grammar rule:
 //
 //	primary -> 	NUMBER | STRING | "true" | "false" | "nil"
@@ -374,10 +745,122 @@ func (p *Parser) parsePrintStatement() (ast.Stmt, error) {
 	}, nil
 }
 
+// parseRange implements the following grammar rule:
+//
+//	range -> comparison ( ( ".." | "..=" ) comparison? )?
+//		   | ( ".." | "..=" ) comparison? ;
+//
+// Either bound of a range may be omitted to leave that side open,
+// as in `s[..5]` or `s[2..]`.
+func (p *Parser) parseRange() (ast.Expr, error) {
+	var start ast.Expr
+
+	isOpen, err := p.checkAny(token.DOT_DOT, token.DOT_DOT_EQUAL)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isOpen {
+		start, err = p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	isMatch, err := p.match(token.DOT_DOT, token.DOT_DOT_EQUAL)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isMatch {
+		return start, nil
+	}
+
+	operator, err := p.previous()
+	if err != nil {
+		return nil, err
+	}
+
+	// The end bound is omitted when the range is immediately followed by
+	// something that can't start an expression.
+	var end ast.Expr
+
+	next, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+
+	if startsExpression(next) {
+		end, err = p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ast.RangeExpr{
+		Start:    start,
+		Operator: operator,
+		End:      end,
+	}, nil
+}
+
+// parseRangePattern parses the rest of a range pattern after its operator.
+// The end bound is omitted when the operator is followed by "=>".
+func (p *Parser) parseRangePattern(start any, operator *token.Token) (ast.Pattern, error) {
+	pattern := &ast.RangePattern{
+		Start:    start,
+		Operator: operator,
+	}
+
+	isOpen, err := p.check(token.EQUAL_GREATER)
+	if err != nil {
+		return nil, err
+	}
+
+	if isOpen {
+		return pattern, nil
+	}
+
+	tok, err := p.advance()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok.Type != token.MINUS && tok.Type != token.NUMBER {
+		return nil, errors.New(ErrExpectPattern)
+	}
+
+	pattern.End, err = p.parsePatternNumber(tok)
+	if err != nil {
+		return nil, err
+	}
+
+	return pattern, nil
+}
+
 // parseStatement implements the following grammar rule:
 //
-//	statement -> exprStmt | printStmt ;
+//	statement -> assertStmt | exprStmt | matchStmt | printStmt ;
 func (p *Parser) parseStatement() (ast.Stmt, error) {
+	defer p.leave()
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+
+	isAssert, err := p.match(token.ASSERT)
+	if err != nil {
+		return nil, err
+	} else if isAssert {
+		return p.parseAssertStatement()
+	}
+
+	isMatch, err := p.match(token.MATCH)
+	if err != nil {
+		return nil, err
+	} else if isMatch {
+		return p.parseMatchStatement()
+	}
+
 	isPrint, err := p.match(token.PRINT)
 	if err != nil {
 		return nil, err
@@ -392,6 +875,8 @@ func (p *Parser) parseStatement() (ast.Stmt, error) {
 //
 //	term -> factor ( ( "-" | "+" ) factor )* ;
 func (p *Parser) parseTerm() (ast.Expr, error) {
+	defer p.unwind(p.depth)
+
 	expr, err := p.parseFactor()
 	if err != nil {
 		return nil, err
@@ -407,6 +892,10 @@ func (p *Parser) parseTerm() (ast.Expr, error) {
 			break
 		}
 
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+
 		operator, err := p.previous()
 		if err != nil {
 			return nil, err
@@ -430,8 +919,13 @@ func (p *Parser) parseTerm() (ast.Expr, error) {
 // parseUnary implements the following grammar rule:
 //
 //	unary -> ( "!" | "-" ) unary
-//		 	 | primary
+//		 	 | index
 func (p *Parser) parseUnary() (ast.Expr, error) {
+	defer p.leave()
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+
 	isMatch, err := p.match(token.BANG, token.MINUS)
 	if err != nil {
 		return nil, err
@@ -454,7 +948,7 @@ func (p *Parser) parseUnary() (ast.Expr, error) {
 		}, nil
 	}
 
-	return p.parsePrimary()
+	return p.parseIndex()
 }
 
 // peek is a one-token lookahead, returning the current token without consuming it.
@@ -498,7 +992,7 @@ func (p *Parser) synchronize() error {
 		}
 
 		switch nextToken.Type {
-		case token.CLASS, token.FOR, token.FUN, token.IF, token.PRINT, token.RETURN, token.VAR, token.WHILE:
+		case token.ASSERT, token.CLASS, token.FOR, token.FUN, token.IF, token.MATCH, token.PRINT, token.RETURN, token.VAR, token.WHILE:
 			return nil
 		}
 
@@ -508,3 +1002,84 @@ func (p *Parser) synchronize() error {
 		}
 	}
 }
+
+// unwind restores the nesting depth on return from a rule that calls enter
+// each time it wraps the expression parsed so far in a new node.
+func (p *Parser) unwind(depth int) {
+	p.depth = depth
+}
+
+// insertSemicolons returns a copy of tokens with a ';' inserted wherever a
+// statement ends without one. Much like in Go, whether a line ends a statement
+// depends only on the last token of that line. A ';' is inserted after a
+// token that can end an expression (a literal, an identifier, "this",
+// "super", ")", "]", or the ".." or "..=" of a range without an end bound)
+// when it is followed by:
+//
+//   - a newline,
+//   - the end of the input, or
+//   - a closing "}", so that `{ case 1 => print 1 }` needs no ';'.
+//
+// No ';' is inserted before an explicit one, even on the next line, so
+// that explicit semicolons are always accepted.
+//
+// Newlines inside parentheses or brackets never end a statement.
+// Everywhere else, a line that ends with an operator other than ".." or
+// "..=", a ',' or an opening brace continues on the next line. This has
+// three consequences: a binary operator that splits an expression across
+// lines must end the first line rather than start the second, a range's
+// end bound must be on the same line as its operator, and the opening "{"
+// of a match statement must be on the same line as its subject.
+func insertSemicolons(tokens []*token.Token) []*token.Token {
+	result := make([]*token.Token, 0, len(tokens))
+
+	// depth counts the parentheses and brackets that are currently open
+	depth := 0
+
+	for idx, tok := range tokens {
+		if idx > 0 && depth == 0 && endsExpression(tokens[idx-1]) && tok.Type != token.SEMICOLON &&
+			(tok.NewlineBefore || tok.Type == token.EOF || tok.Type == token.RIGHT_BRACE) {
+			result = append(result, &token.Token{
+				Lexeme: "\n",
+				Line:   tokens[idx-1].Line,
+				Type:   token.SEMICOLON,
+			})
+		}
+
+		switch tok.Type {
+		case token.LEFT_PAREN, token.LEFT_BRACKET, token.QUESTION_LEFT_BRACKET:
+			depth++
+		case token.RIGHT_PAREN, token.RIGHT_BRACKET:
+			if depth > 0 {
+				depth--
+			}
+		}
+
+		result = append(result, tok)
+	}
+
+	return result
+}
+
+// endsExpression returns true if the token can be the last one in an expression.
+func endsExpression(tok *token.Token) bool {
+	switch tok.Type {
+	case token.DOT_DOT, token.DOT_DOT_EQUAL, token.FALSE, token.IDENTIFIER, token.NIL, token.NUMBER,
+		token.RIGHT_BRACKET, token.RIGHT_PAREN, token.STRING, token.SUPER, token.THIS, token.TRUE:
+		return true
+	}
+
+	return false
+}
+
+// startsExpression returns true if the token can be the first one in an
+// expression.
+func startsExpression(tok *token.Token) bool {
+	switch tok.Type {
+	case token.BANG, token.FALSE, token.IDENTIFIER, token.LEFT_PAREN, token.MINUS, token.NIL,
+		token.NUMBER, token.STRING, token.SUPER, token.THIS, token.TRUE:
+		return true
+	}
+
+	return false
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/doeg/golox/golox/ast"
@@ -158,6 +159,219 @@ func TestParseExpression(t *testing.T) {
 				Right: &ast.LiteralExpr{Value: true},
 			},
 		},
+		{
+			input: "1..3",
+			expected: &ast.RangeExpr{
+				Start: &ast.LiteralExpr{Value: float64(1)},
+				Operator: &token.Token{
+					Lexeme: "..",
+					Line:   0,
+					Type:   token.DOT_DOT,
+				},
+				End: &ast.LiteralExpr{Value: float64(3)},
+			},
+		},
+		{
+			input: "1..=2 + 1",
+			expected: &ast.RangeExpr{
+				Start: &ast.LiteralExpr{Value: float64(1)},
+				Operator: &token.Token{
+					Lexeme: "..=",
+					Line:   0,
+					Type:   token.DOT_DOT_EQUAL,
+				},
+				End: &ast.BinaryExpr{
+					Left: &ast.LiteralExpr{Value: float64(2)},
+					Operator: &token.Token{
+						Lexeme: "+",
+						Line:   0,
+						Type:   token.PLUS,
+					},
+					Right: &ast.LiteralExpr{Value: float64(1)},
+				},
+			},
+		},
+		{
+			input: "\"hello\"[..2]",
+			expected: &ast.IndexExpr{
+				Object: &ast.LiteralExpr{Value: "hello"},
+				Bracket: &token.Token{
+					Lexeme: "[",
+					Line:   0,
+					Type:   token.LEFT_BRACKET,
+				},
+				Index: &ast.RangeExpr{
+					Operator: &token.Token{
+						Lexeme: "..",
+						Line:   0,
+						Type:   token.DOT_DOT,
+					},
+					End: &ast.LiteralExpr{Value: float64(2)},
+				},
+			},
+		},
+		{
+			input: "\"hello\"[2..][0]",
+			expected: &ast.IndexExpr{
+				Object: &ast.IndexExpr{
+					Object: &ast.LiteralExpr{Value: "hello"},
+					Bracket: &token.Token{
+						Lexeme: "[",
+						Line:   0,
+						Type:   token.LEFT_BRACKET,
+					},
+					Index: &ast.RangeExpr{
+						Start: &ast.LiteralExpr{Value: float64(2)},
+						Operator: &token.Token{
+							Lexeme: "..",
+							Line:   0,
+							Type:   token.DOT_DOT,
+						},
+					},
+				},
+				Bracket: &token.Token{
+					Lexeme: "[",
+					Line:   0,
+					Type:   token.LEFT_BRACKET,
+				},
+				Index: &ast.LiteralExpr{Value: float64(0)},
+			},
+		},
+		{
+			input: "nil ?? 1 == 2",
+			expected: &ast.LogicalExpr{
+				Left: &ast.LiteralExpr{Value: nil},
+				Operator: &token.Token{
+					Lexeme: "??",
+					Line:   0,
+					Type:   token.QUESTION_QUESTION,
+				},
+				Right: &ast.BinaryExpr{
+					Left: &ast.LiteralExpr{Value: float64(1)},
+					Operator: &token.Token{
+						Lexeme: "==",
+						Line:   0,
+						Type:   token.EQUAL_EQUAL,
+					},
+					Right: &ast.LiteralExpr{Value: float64(2)},
+				},
+			},
+		},
+		{
+			input: "nil?[0] ?? \"a\"",
+			expected: &ast.LogicalExpr{
+				Left: &ast.IndexExpr{
+					Object: &ast.LiteralExpr{Value: nil},
+					Bracket: &token.Token{
+						Lexeme: "?[",
+						Line:   0,
+						Type:   token.QUESTION_LEFT_BRACKET,
+					},
+					Index: &ast.LiteralExpr{Value: float64(0)},
+				},
+				Operator: &token.Token{
+					Lexeme: "??",
+					Line:   0,
+					Type:   token.QUESTION_QUESTION,
+				},
+				Right: &ast.LiteralExpr{Value: "a"},
+			},
+		},
+		{
+			input: "1.. == 1..",
+			expected: &ast.BinaryExpr{
+				Left: &ast.RangeExpr{
+					Start: &ast.LiteralExpr{Value: float64(1)},
+					Operator: &token.Token{
+						Lexeme: "..",
+						Line:   0,
+						Type:   token.DOT_DOT,
+					},
+				},
+				Operator: &token.Token{
+					Lexeme: "==",
+					Line:   0,
+					Type:   token.EQUAL_EQUAL,
+				},
+				Right: &ast.RangeExpr{
+					Start: &ast.LiteralExpr{Value: float64(1)},
+					Operator: &token.Token{
+						Lexeme: "..",
+						Line:   0,
+						Type:   token.DOT_DOT,
+					},
+				},
+			},
+		},
+		{
+			input: "1.. != 2..",
+			expected: &ast.BinaryExpr{
+				Left: &ast.RangeExpr{
+					Start: &ast.LiteralExpr{Value: float64(1)},
+					Operator: &token.Token{
+						Lexeme: "..",
+						Line:   0,
+						Type:   token.DOT_DOT,
+					},
+				},
+				Operator: &token.Token{
+					Lexeme: "!=",
+					Line:   0,
+					Type:   token.BANG_EQUAL,
+				},
+				Right: &ast.RangeExpr{
+					Start: &ast.LiteralExpr{Value: float64(2)},
+					Operator: &token.Token{
+						Lexeme: "..",
+						Line:   0,
+						Type:   token.DOT_DOT,
+					},
+				},
+			},
+		},
+		{
+			input: "1.. ?? 2",
+			expected: &ast.LogicalExpr{
+				Left: &ast.RangeExpr{
+					Start: &ast.LiteralExpr{Value: float64(1)},
+					Operator: &token.Token{
+						Lexeme: "..",
+						Line:   0,
+						Type:   token.DOT_DOT,
+					},
+				},
+				Operator: &token.Token{
+					Lexeme: "??",
+					Line:   0,
+					Type:   token.QUESTION_QUESTION,
+				},
+				Right: &ast.LiteralExpr{Value: float64(2)},
+			},
+		},
+		{
+			input: "1..-2",
+			expected: &ast.RangeExpr{
+				Start: &ast.LiteralExpr{Value: float64(1)},
+				Operator: &token.Token{
+					Lexeme: "..",
+					Line:   0,
+					Type:   token.DOT_DOT,
+				},
+				End: &ast.UnaryExpr{
+					Operator: &token.Token{
+						Lexeme: "-",
+						Line:   0,
+						Type:   token.MINUS,
+					},
+					Right: &ast.LiteralExpr{Value: float64(2)},
+				},
+			},
+		},
+		{
+			testName:      "error: missing closing bracket",
+			input:         "\"hello\"[1",
+			expectedError: errors.New("expect ']' after index"),
+		},
 		{
 			testName:      "error: expected expression",
 			input:         "* 1",
@@ -227,3 +441,570 @@ func TestSynchronize(t *testing.T) {
 		})
 	}
 }
+
+func TestParseMatchStatement(t *testing.T) {
+	tests := []struct {
+		testName      string
+		input         string
+		expected      []ast.Stmt
+		expectedError error
+	}{
+		{
+			input: "match (1) { case 1 => print 1; case _ => print 2; }",
+			expected: []ast.Stmt{
+				&ast.MatchStmt{
+					Keyword: &token.Token{
+						Lexeme: "match",
+						Line:   0,
+						Type:   token.MATCH,
+					},
+					Subject: &ast.LiteralExpr{Value: float64(1)},
+					Patterns: []ast.Pattern{
+						&ast.LiteralPattern{
+							Token: &token.Token{
+								Lexeme:  "1",
+								Line:    0,
+								Literal: float64(1),
+								Type:    token.NUMBER,
+							},
+							Value: float64(1),
+						},
+						&ast.WildcardPattern{
+							Keyword: &token.Token{
+								Lexeme: "_",
+								Line:   0,
+								Type:   token.IDENTIFIER,
+							},
+						},
+					},
+					Bodies: []ast.Stmt{
+						&ast.PrintStmt{Expression: &ast.LiteralExpr{Value: float64(1)}},
+						&ast.PrintStmt{Expression: &ast.LiteralExpr{Value: float64(2)}},
+					},
+				},
+			},
+		},
+		{
+			input: "match (\"a\") { case -1 => print 1; case \"a\" => print 2; case nil => print 3; }",
+			expected: []ast.Stmt{
+				&ast.MatchStmt{
+					Keyword: &token.Token{
+						Lexeme: "match",
+						Line:   0,
+						Type:   token.MATCH,
+					},
+					Subject: &ast.LiteralExpr{Value: "a"},
+					Patterns: []ast.Pattern{
+						&ast.LiteralPattern{
+							Token: &token.Token{
+								Lexeme: "-",
+								Line:   0,
+								Type:   token.MINUS,
+							},
+							Value: float64(-1),
+						},
+						&ast.LiteralPattern{
+							Token: &token.Token{
+								Lexeme:  "\"a\"",
+								Line:    0,
+								Literal: "a",
+								Type:    token.STRING,
+							},
+							Value: "a",
+						},
+						&ast.LiteralPattern{
+							Token: &token.Token{
+								Lexeme: "nil",
+								Line:   0,
+								Type:   token.NIL,
+							},
+							Value: nil,
+						},
+					},
+					Bodies: []ast.Stmt{
+						&ast.PrintStmt{Expression: &ast.LiteralExpr{Value: float64(1)}},
+						&ast.PrintStmt{Expression: &ast.LiteralExpr{Value: float64(2)}},
+						&ast.PrintStmt{Expression: &ast.LiteralExpr{Value: float64(3)}},
+					},
+				},
+			},
+		},
+		{
+			input: "match (1) { case 1..2 => print 1; case ..=-1 => print 2; case 3.. => print 3; }",
+			expected: []ast.Stmt{
+				&ast.MatchStmt{
+					Keyword: &token.Token{
+						Lexeme: "match",
+						Line:   0,
+						Type:   token.MATCH,
+					},
+					Subject: &ast.LiteralExpr{Value: float64(1)},
+					Patterns: []ast.Pattern{
+						&ast.RangePattern{
+							Start: float64(1),
+							Operator: &token.Token{
+								Lexeme: "..",
+								Line:   0,
+								Type:   token.DOT_DOT,
+							},
+							End: float64(2),
+						},
+						&ast.RangePattern{
+							Operator: &token.Token{
+								Lexeme: "..=",
+								Line:   0,
+								Type:   token.DOT_DOT_EQUAL,
+							},
+							End: float64(-1),
+						},
+						&ast.RangePattern{
+							Start: float64(3),
+							Operator: &token.Token{
+								Lexeme: "..",
+								Line:   0,
+								Type:   token.DOT_DOT,
+							},
+						},
+					},
+					Bodies: []ast.Stmt{
+						&ast.PrintStmt{Expression: &ast.LiteralExpr{Value: float64(1)}},
+						&ast.PrintStmt{Expression: &ast.LiteralExpr{Value: float64(2)}},
+						&ast.PrintStmt{Expression: &ast.LiteralExpr{Value: float64(3)}},
+					},
+				},
+			},
+		},
+		{
+			input: "match (true) { }",
+			expected: []ast.Stmt{
+				&ast.MatchStmt{
+					Keyword: &token.Token{
+						Lexeme: "match",
+						Line:   0,
+						Type:   token.MATCH,
+					},
+					Subject:  &ast.LiteralExpr{Value: true},
+					Patterns: []ast.Pattern{},
+					Bodies:   []ast.Stmt{},
+				},
+			},
+		},
+		{
+			testName:      "error: non-literal pattern",
+			input:         "match (1) { case 1 + 1 => print 1; }",
+			expectedError: errors.New("expect '=>' after pattern"),
+		},
+		{
+			testName:      "error: missing pattern",
+			input:         "match (1) { case => print 1; }",
+			expectedError: errors.New(ErrExpectPattern),
+		},
+		{
+			testName:      "error: identifier pattern",
+			input:         "match (1) { case x => print 1; }",
+			expectedError: errors.New(ErrExpectPattern),
+		},
+		{
+			testName:      "error: non-number range bound",
+			input:         "match (1) { case 1..\"a\" => print 1; }",
+			expectedError: errors.New(ErrExpectPattern),
+		},
+		{
+			testName:      "error: unterminated match",
+			input:         "match (1) { case 1 => print 1;",
+			expectedError: errors.New("expect '}' after match cases"),
+		},
+	}
+
+	for _, tt := range tests {
+		testName := tt.testName
+		if tt.testName == "" {
+			testName = tt.input
+		}
+
+		tt := tt
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			s := scanner.New([]byte(tt.input))
+			tokens, errors := s.ScanTokens()
+			require.Empty(t, errors)
+
+			p := New(tokens)
+			stmts, err := p.Parse()
+
+			if tt.expectedError != nil {
+				assert.Nil(t, stmts)
+				assert.Equal(t, tt.expectedError, err)
+			} else {
+				assert.EqualValues(t, tt.expected, stmts)
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestParseAssertStatement(t *testing.T) {
+	tests := []struct {
+		testName      string
+		input         string
+		expected      []ast.Stmt
+		expectedError error
+	}{
+		{
+			input: "assert true;",
+			expected: []ast.Stmt{
+				&ast.AssertStmt{
+					Keyword: &token.Token{
+						Lexeme: "assert",
+						Line:   0,
+						Type:   token.ASSERT,
+					},
+					Condition: &ast.LiteralExpr{Value: true},
+				},
+			},
+		},
+		{
+			input: "assert 1 == 2, \"message\";",
+			expected: []ast.Stmt{
+				&ast.AssertStmt{
+					Keyword: &token.Token{
+						Lexeme: "assert",
+						Line:   0,
+						Type:   token.ASSERT,
+					},
+					Condition: &ast.BinaryExpr{
+						Left: &ast.LiteralExpr{Value: float64(1)},
+						Operator: &token.Token{
+							Lexeme: "==",
+							Line:   0,
+							Type:   token.EQUAL_EQUAL,
+						},
+						Right: &ast.LiteralExpr{Value: float64(2)},
+					},
+					Message: &ast.LiteralExpr{Value: "message"},
+				},
+			},
+		},
+		{
+			input: "assert 1.., \"m\";",
+			expected: []ast.Stmt{
+				&ast.AssertStmt{
+					Keyword: &token.Token{
+						Lexeme: "assert",
+						Line:   0,
+						Type:   token.ASSERT,
+					},
+					Condition: &ast.RangeExpr{
+						Start: &ast.LiteralExpr{Value: float64(1)},
+						Operator: &token.Token{
+							Lexeme: "..",
+							Line:   0,
+							Type:   token.DOT_DOT,
+						},
+					},
+					Message: &ast.LiteralExpr{Value: "m"},
+				},
+			},
+		},
+		{
+			testName:      "error: missing semicolon",
+			input:         "assert true",
+			expectedError: errors.New("expect ';' after assertion"),
+		},
+		{
+			testName:      "error: missing message",
+			input:         "assert true, ;",
+			expectedError: errors.New(ErrExpectExpression),
+		},
+	}
+
+	for _, tt := range tests {
+		testName := tt.testName
+		if tt.testName == "" {
+			testName = tt.input
+		}
+
+		tt := tt
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			s := scanner.New([]byte(tt.input))
+			tokens, errors := s.ScanTokens()
+			require.Empty(t, errors)
+
+			p := New(tokens)
+			stmts, err := p.Parse()
+
+			if tt.expectedError != nil {
+				assert.Nil(t, stmts)
+				assert.Equal(t, tt.expectedError, err)
+			} else {
+				assert.EqualValues(t, tt.expected, stmts)
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestOptionalSemicolons(t *testing.T) {
+	tests := []struct {
+		testName      string
+		input         string
+		expected      []string
+		expectedError error
+	}{
+		{
+			input:    "print 1\nprint 2",
+			expected: []string{"print 1", "print 2"},
+		},
+		{
+			input:    "print 1; print 2;\nprint 3",
+			expected: []string{"print 1", "print 2", "print 3"},
+		},
+		{
+			testName: "explicit semicolon on the next line",
+			input:    "print 1\n;\nprint 2\n;",
+			expected: []string{"print 1", "print 2"},
+		},
+		{
+			testName: "trailing operator continues the line",
+			input:    "print 1 +\n2 *\n3\n",
+			expected: []string{"print 1 + 2 * 3"},
+		},
+		{
+			testName: "leading operator starts a new statement",
+			input:    "print 1\n-2",
+			expected: []string{"print 1", "-2"},
+		},
+		{
+			testName: "newlines inside parentheses are ignored",
+			input:    "print (1\n+ 2\n)\nprint 3",
+			expected: []string{"print (1 + 2)", "print 3"},
+		},
+		{
+			testName: "newlines inside brackets are ignored",
+			input:    "print \"hello\"[1\n..\n3]",
+			expected: []string{"print \"hello\"[1..3]"},
+		},
+		{
+			input:    "nil ??\n\"a\"",
+			expected: []string{"nil ?? \"a\""},
+		},
+		{
+			testName: "comments don't hide newlines",
+			input:    "print 1 // one\nprint 2",
+			expected: []string{"print 1", "print 2"},
+		},
+		{
+			testName: "closing brace ends a statement",
+			input:    "match (1) { case 1 => print 1 }",
+			expected: []string{"match"},
+		},
+		{
+			input:    "match (1) {\ncase 1 => print 1\ncase _ => print 2\n}\nprint 3",
+			expected: []string{"match", "print 3"},
+		},
+		{
+			input:    "assert 1 ==\n1,\n\"message\"\nprint 1",
+			expected: []string{"assert", "print 1"},
+		},
+		{
+			testName: "open range ends a line",
+			input:    "print 1..\nprint 2..=\n1..",
+			expected: []string{"print 1..", "print 2..=", "1.."},
+		},
+		{
+			testName: "open range before a closing brace",
+			input:    "match (1) { case 1 => print 1.. }",
+			expected: []string{"match"},
+		},
+		{
+			testName: "open range before an explicit semicolon",
+			input:    "print 1..;\nprint ..;",
+			expected: []string{"print 1..", "print .."},
+		},
+		{
+			testName: "range end on the same line",
+			input:    "print \"hello\"[1..\n3]\nprint 1..3",
+			expected: []string{"print \"hello\"[1..3]", "print 1..3"},
+		},
+		{
+			testName:      "error: two statements on one line",
+			input:         "print 1 print 2",
+			expectedError: errors.New("expect ';' after expression"),
+		},
+		{
+			testName:      "error: brace on the next line",
+			input:         "match (1)\n{ case 1 => print 1 }",
+			expectedError: errors.New("expect '{' before match cases"),
+		},
+	}
+
+	for _, tt := range tests {
+		testName := tt.testName
+		if tt.testName == "" {
+			testName = tt.input
+		}
+
+		tt := tt
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			s := scanner.New([]byte(tt.input))
+			tokens, errors := s.ScanTokens()
+			require.Empty(t, errors)
+
+			p := New(tokens, OptionalSemicolons())
+			stmts, err := p.Parse()
+
+			if tt.expectedError != nil {
+				assert.Nil(t, stmts)
+				assert.Equal(t, tt.expectedError, err)
+				return
+			}
+
+			require.Nil(t, err)
+
+			actual := make([]string, 0)
+			for _, stmt := range stmts {
+				switch stmt := stmt.(type) {
+				case *ast.AssertStmt:
+					actual = append(actual, "assert")
+				case *ast.ExpressionStmt:
+					actual = append(actual, ast.Print(stmt.Expression))
+				case *ast.MatchStmt:
+					actual = append(actual, "match")
+				case *ast.PrintStmt:
+					actual = append(actual, "print "+ast.Print(stmt.Expression))
+				}
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestSemicolonsRequiredByDefault(t *testing.T) {
+	s := scanner.New([]byte("print 1\nprint 2"))
+	tokens, errs := s.ScanTokens()
+	require.Empty(t, errs)
+
+	p := New(tokens)
+	stmts, err := p.Parse()
+	assert.Nil(t, stmts)
+	assert.Equal(t, errors.New("expect ';' after expression"), err)
+}
+
+func TestNestingLimit(t *testing.T) {
+	// nested returns n parens around a literal
+	nested := func(n int) string {
+		return strings.Repeat("(", n) + "1" + strings.Repeat(")", n)
+	}
+
+	// chain returns a flat chain of n operands joined by op
+	chain := func(n int, op string) string {
+		return "1" + strings.Repeat(op+"1", n-1)
+	}
+
+	tests := []struct {
+		testName      string
+		input         string
+		expectedError error
+	}{
+		{
+			testName:      "opening parens",
+			input:         strings.Repeat("(", 100000) + "1",
+			expectedError: errors.New(ErrTooDeeplyNested),
+		},
+		{
+			testName:      "unary operators",
+			input:         strings.Repeat("-", 100000) + "1",
+			expectedError: errors.New(ErrTooDeeplyNested),
+		},
+		{
+			testName: "parens just under the limit",
+			input:    nested(maxDepth - 1),
+		},
+		{
+			testName:      "parens at the limit",
+			input:         nested(maxDepth),
+			expectedError: errors.New(ErrTooDeeplyNested),
+		},
+		{
+			testName: "unary operators just under the limit",
+			input:    strings.Repeat("!", maxDepth-1) + "true",
+		},
+		{
+			testName: "chain just under the limit",
+			input:    chain(maxDepth, " + "),
+		},
+		{
+			testName:      "chain over the limit",
+			input:         chain(maxDepth+1, " + "),
+			expectedError: errors.New(ErrTooDeeplyNested),
+		},
+		{
+			testName:      "long flat chain of additions",
+			input:         chain(100000, " + "),
+			expectedError: errors.New(ErrTooDeeplyNested),
+		},
+		{
+			testName:      "long flat chain of divisions",
+			input:         chain(100000, " / "),
+			expectedError: errors.New(ErrTooDeeplyNested),
+		},
+		{
+			testName:      "long flat chain of comparisons",
+			input:         chain(100000, " == "),
+			expectedError: errors.New(ErrTooDeeplyNested),
+		},
+		{
+			testName:      "long flat chain of coalescing",
+			input:         chain(100000, " ?? "),
+			expectedError: errors.New(ErrTooDeeplyNested),
+		},
+		{
+			testName:      "long chain of indexes",
+			input:         "\"a\"" + strings.Repeat("[0]", 100000),
+			expectedError: errors.New(ErrTooDeeplyNested),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.testName, func(t *testing.T) {
+			t.Parallel()
+
+			s := scanner.New([]byte(tt.input))
+			tokens, errs := s.ScanTokens()
+			require.Empty(t, errs)
+
+			p := New(tokens)
+			expr, err := p.ParseExpression()
+
+			if tt.expectedError != nil {
+				assert.Nil(t, expr)
+				assert.Equal(t, tt.expectedError, err)
+			} else {
+				assert.NotNil(t, expr)
+				assert.Nil(t, err)
+				assert.Equal(t, 0, p.depth)
+			}
+		})
+	}
+}
+
+func TestNestingLimitStatements(t *testing.T) {
+	// The depth unwinds after each statement, so many long statements in a
+	// row are fine as long as none of them is too deep on its own.
+	line := "print 1" + strings.Repeat(" + 1", maxDepth/2) + ";\n"
+
+	s := scanner.New([]byte(strings.Repeat(line, 10)))
+	tokens, errs := s.ScanTokens()
+	require.Empty(t, errs)
+
+	p := New(tokens)
+	stmts, err := p.Parse()
+	require.Nil(t, err)
+	assert.Len(t, stmts, 10)
+}
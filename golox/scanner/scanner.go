@@ -22,6 +22,10 @@ type Scanner struct {
 	// tokens that know their location
 	line int
 
+	// newline is true if a line break has been seen since the last token
+	// was added, so the next token can record that it starts a new line
+	newline bool
+
 	// errors accumulates syntax errors as the scanner progresses
 	// so as many errors as possible can be collected in a single scan pass
 	errors []loxerror.LoxError
@@ -42,7 +46,11 @@ func (scanner *Scanner) ScanTokens() ([]*token.Token, []loxerror.LoxError) {
 		scanner.scanToken()
 	}
 
-	scanner.tokens = append(scanner.tokens, &token.Token{Line: scanner.line, Type: token.EOF})
+	scanner.tokens = append(scanner.tokens, &token.Token{
+		Line:          scanner.line,
+		NewlineBefore: scanner.newline,
+		Type:          token.EOF,
+	})
 
 	return scanner.tokens, scanner.errors
 }
@@ -56,11 +64,13 @@ func (scanner *Scanner) addOperatorToken(tokenType token.TokenType) {
 func (scanner *Scanner) addToken(tokenType token.TokenType, literal interface{}) {
 	text := scanner.source[scanner.start:scanner.current]
 	scanner.tokens = append(scanner.tokens, &token.Token{
-		Lexeme:  string(text),
-		Line:    scanner.line,
-		Literal: literal,
-		Type:    tokenType,
+		Lexeme:        string(text),
+		Line:          scanner.line,
+		Literal:       literal,
+		NewlineBefore: scanner.newline,
+		Type:          tokenType,
 	})
+	scanner.newline = false
 }
 
 // advance consumes the next ASCII character in the source file and returns it.
@@ -184,10 +194,22 @@ func (scanner *Scanner) scanToken() {
 		scanner.addOperatorToken(token.LEFT_BRACE)
 	case '}':
 		scanner.addOperatorToken(token.RIGHT_BRACE)
+	case '[':
+		scanner.addOperatorToken(token.LEFT_BRACKET)
+	case ']':
+		scanner.addOperatorToken(token.RIGHT_BRACKET)
 	case ',':
 		scanner.addOperatorToken(token.COMMA)
 	case '.':
-		scanner.addOperatorToken(token.DOT)
+		if scanner.match('.') {
+			if scanner.match('=') {
+				scanner.addOperatorToken(token.DOT_DOT_EQUAL)
+			} else {
+				scanner.addOperatorToken(token.DOT_DOT)
+			}
+		} else {
+			scanner.addOperatorToken(token.DOT)
+		}
 	case '-':
 		scanner.addOperatorToken(token.MINUS)
 	case '+':
@@ -211,6 +233,8 @@ func (scanner *Scanner) scanToken() {
 	case '=':
 		if scanner.match('=') {
 			scanner.addOperatorToken(token.EQUAL_EQUAL)
+		} else if scanner.match('>') {
+			scanner.addOperatorToken(token.EQUAL_GREATER)
 		} else {
 			scanner.addOperatorToken(token.EQUAL)
 		}
@@ -226,12 +250,25 @@ func (scanner *Scanner) scanToken() {
 		} else {
 			scanner.addOperatorToken(token.LESS)
 		}
+	case '?':
+		// Numbers can't start with a '.', so "?." is always the
+		// null-safe property operator and never a '?' followed by a number.
+		if scanner.match('.') {
+			scanner.addOperatorToken(token.QUESTION_DOT)
+		} else if scanner.match('[') {
+			scanner.addOperatorToken(token.QUESTION_LEFT_BRACKET)
+		} else if scanner.match('?') {
+			scanner.addOperatorToken(token.QUESTION_QUESTION)
+		} else {
+			scanner.addOperatorToken(token.QUESTION)
+		}
 	case '"':
 		scanner.scanString()
 	case ' ', '\r', '\t':
 		// Continue, ignoring whitespace
 	case '\n':
 		scanner.line++
+		scanner.newline = true
 	default:
 		switch {
 		case isDigit(b):
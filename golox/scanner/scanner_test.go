@@ -67,6 +67,60 @@ func TestScanTokens(t *testing.T) {
 				{Line: 0, Type: token.EOF},
 			},
 		},
+		{
+			input: "s[1..2] s[..=-1] 1.5.x",
+			expected: []*token.Token{
+				{Line: 0, Lexeme: "s", Type: token.IDENTIFIER},
+				{Line: 0, Lexeme: "[", Type: token.LEFT_BRACKET},
+				{Line: 0, Lexeme: "1", Literal: float64(1), Type: token.NUMBER},
+				{Line: 0, Lexeme: "..", Type: token.DOT_DOT},
+				{Line: 0, Lexeme: "2", Literal: float64(2), Type: token.NUMBER},
+				{Line: 0, Lexeme: "]", Type: token.RIGHT_BRACKET},
+				{Line: 0, Lexeme: "s", Type: token.IDENTIFIER},
+				{Line: 0, Lexeme: "[", Type: token.LEFT_BRACKET},
+				{Line: 0, Lexeme: "..=", Type: token.DOT_DOT_EQUAL},
+				{Line: 0, Lexeme: "-", Type: token.MINUS},
+				{Line: 0, Lexeme: "1", Literal: float64(1), Type: token.NUMBER},
+				{Line: 0, Lexeme: "]", Type: token.RIGHT_BRACKET},
+				{Line: 0, Lexeme: "1.5", Literal: 1.5, Type: token.NUMBER},
+				{Line: 0, Lexeme: ".", Type: token.DOT},
+				{Line: 0, Lexeme: "x", Type: token.IDENTIFIER},
+				{Line: 0, Type: token.EOF},
+			},
+		},
+		{
+			input: "a?.b ?? c?[0] ? d",
+			expected: []*token.Token{
+				{Line: 0, Lexeme: "a", Type: token.IDENTIFIER},
+				{Line: 0, Lexeme: "?.", Type: token.QUESTION_DOT},
+				{Line: 0, Lexeme: "b", Type: token.IDENTIFIER},
+				{Line: 0, Lexeme: "??", Type: token.QUESTION_QUESTION},
+				{Line: 0, Lexeme: "c", Type: token.IDENTIFIER},
+				{Line: 0, Lexeme: "?[", Type: token.QUESTION_LEFT_BRACKET},
+				{Line: 0, Lexeme: "0", Literal: float64(0), Type: token.NUMBER},
+				{Line: 0, Lexeme: "]", Type: token.RIGHT_BRACKET},
+				{Line: 0, Lexeme: "?", Type: token.QUESTION},
+				{Line: 0, Lexeme: "d", Type: token.IDENTIFIER},
+				{Line: 0, Type: token.EOF},
+			},
+		},
+		{
+			input: "match (x) { case _ => y; }",
+			expected: []*token.Token{
+				{Line: 0, Lexeme: "match", Type: token.MATCH},
+				{Line: 0, Lexeme: "(", Type: token.LEFT_PAREN},
+				{Line: 0, Lexeme: "x", Type: token.IDENTIFIER},
+				{Line: 0, Lexeme: ")", Type: token.RIGHT_PAREN},
+				{Line: 0, Lexeme: "{", Type: token.LEFT_BRACE},
+				{Line: 0, Lexeme: "case", Type: token.CASE},
+				{Line: 0, Lexeme: "_", Type: token.IDENTIFIER},
+				{Line: 0, Lexeme: "=>", Type: token.EQUAL_GREATER},
+				{Line: 0, Lexeme: "y", Type: token.IDENTIFIER},
+				{Line: 0, Lexeme: ";", Type: token.SEMICOLON},
+				{Line: 0, Lexeme: "}", Type: token.RIGHT_BRACE},
+				{Line: 0, Type: token.EOF},
+			},
+		},
 		{
 			input: "-12/0.34",
 			expected: []*token.Token{
@@ -126,7 +180,7 @@ func TestScanTokens(t *testing.T) {
 		{
 			input: "// this is a comment\n!=",
 			expected: []*token.Token{
-				{Line: 1, Lexeme: "!=", Type: token.BANG_EQUAL},
+				{Line: 1, Lexeme: "!=", NewlineBefore: true, Type: token.BANG_EQUAL},
 				{Line: 1, Type: token.EOF},
 			},
 		},
@@ -166,13 +220,13 @@ func TestScanTokens(t *testing.T) {
 				}
 			`,
 			expected: []*token.Token{
-				{Line: 1, Lexeme: "var", Type: token.VAR},
+				{Line: 1, Lexeme: "var", NewlineBefore: true, Type: token.VAR},
 				{Line: 1, Lexeme: "a", Type: token.IDENTIFIER},
 				{Line: 1, Lexeme: "=", Type: token.EQUAL},
 				{Line: 1, Lexeme: "1", Literal: float64(1), Type: token.NUMBER},
 				{Line: 1, Lexeme: ";", Type: token.SEMICOLON},
 
-				{Line: 2, Lexeme: "while", Type: token.WHILE},
+				{Line: 2, Lexeme: "while", NewlineBefore: true, Type: token.WHILE},
 				{Line: 2, Lexeme: "(", Type: token.LEFT_PAREN},
 				{Line: 2, Lexeme: "a", Type: token.IDENTIFIER},
 				{Line: 2, Lexeme: "<=", Type: token.LESS_EQUAL},
@@ -180,13 +234,13 @@ func TestScanTokens(t *testing.T) {
 				{Line: 2, Lexeme: ")", Type: token.RIGHT_PAREN},
 				{Line: 2, Lexeme: "{", Type: token.LEFT_BRACE},
 
-				{Line: 3, Lexeme: "print", Type: token.PRINT},
+				{Line: 3, Lexeme: "print", NewlineBefore: true, Type: token.PRINT},
 				{Line: 3, Lexeme: "a", Type: token.IDENTIFIER},
 				{Line: 3, Lexeme: ";", Type: token.SEMICOLON},
 
-				{Line: 4, Lexeme: "}", Type: token.RIGHT_BRACE},
+				{Line: 4, Lexeme: "}", NewlineBefore: true, Type: token.RIGHT_BRACE},
 
-				{Line: 5, Type: token.EOF},
+				{Line: 5, NewlineBefore: true, Type: token.EOF},
 			},
 		},
 		{
@@ -198,27 +252,27 @@ func TestScanTokens(t *testing.T) {
 				}
 			`,
 			expected: []*token.Token{
-				{Line: 1, Lexeme: "if", Type: token.IF},
+				{Line: 1, Lexeme: "if", NewlineBefore: true, Type: token.IF},
 				{Line: 1, Lexeme: "(", Type: token.LEFT_PAREN},
 				{Line: 1, Lexeme: "condition", Type: token.IDENTIFIER},
 				{Line: 1, Lexeme: ")", Type: token.RIGHT_PAREN},
 				{Line: 1, Lexeme: "{", Type: token.LEFT_BRACE},
 
-				{Line: 2, Lexeme: "print", Type: token.PRINT},
+				{Line: 2, Lexeme: "print", NewlineBefore: true, Type: token.PRINT},
 				{Line: 2, Lexeme: "\"yes\"", Literal: "yes", Type: token.STRING},
 				{Line: 2, Lexeme: ";", Type: token.SEMICOLON},
 
-				{Line: 3, Lexeme: "}", Type: token.RIGHT_BRACE},
+				{Line: 3, Lexeme: "}", NewlineBefore: true, Type: token.RIGHT_BRACE},
 				{Line: 3, Lexeme: "else", Type: token.ELSE},
 				{Line: 3, Lexeme: "{", Type: token.LEFT_BRACE},
 
-				{Line: 4, Lexeme: "print", Type: token.PRINT},
+				{Line: 4, Lexeme: "print", NewlineBefore: true, Type: token.PRINT},
 				{Line: 4, Lexeme: "\"no\"", Literal: "no", Type: token.STRING},
 				{Line: 4, Lexeme: ";", Type: token.SEMICOLON},
 
-				{Line: 5, Lexeme: "}", Type: token.RIGHT_BRACE},
+				{Line: 5, Lexeme: "}", NewlineBefore: true, Type: token.RIGHT_BRACE},
 
-				{Line: 6, Type: token.EOF},
+				{Line: 6, NewlineBefore: true, Type: token.EOF},
 			},
 		},
 		{
@@ -228,7 +282,7 @@ func TestScanTokens(t *testing.T) {
 				}
 			`,
 			expected: []*token.Token{
-				{Line: 1, Lexeme: "for", Type: token.FOR},
+				{Line: 1, Lexeme: "for", NewlineBefore: true, Type: token.FOR},
 				{Line: 1, Lexeme: "(", Type: token.LEFT_PAREN},
 				{Line: 1, Lexeme: "var", Type: token.VAR},
 				{Line: 1, Lexeme: "a", Type: token.IDENTIFIER},
@@ -247,13 +301,13 @@ func TestScanTokens(t *testing.T) {
 				{Line: 1, Lexeme: ")", Type: token.RIGHT_PAREN},
 				{Line: 1, Lexeme: "{", Type: token.LEFT_BRACE},
 
-				{Line: 2, Lexeme: "print", Type: token.PRINT},
+				{Line: 2, Lexeme: "print", NewlineBefore: true, Type: token.PRINT},
 				{Line: 2, Lexeme: "a", Type: token.IDENTIFIER},
 				{Line: 2, Lexeme: ";", Type: token.SEMICOLON},
 
-				{Line: 3, Lexeme: "}", Type: token.RIGHT_BRACE},
+				{Line: 3, Lexeme: "}", NewlineBefore: true, Type: token.RIGHT_BRACE},
 
-				{Line: 4, Type: token.EOF},
+				{Line: 4, NewlineBefore: true, Type: token.EOF},
 			},
 		},
 		{
@@ -282,34 +336,34 @@ func TestScanTokens(t *testing.T) {
 				}
 			`,
 			expected: []*token.Token{
-				{Line: 1, Lexeme: "fun", Type: token.FUN},
+				{Line: 1, Lexeme: "fun", NewlineBefore: true, Type: token.FUN},
 				{Line: 1, Lexeme: "outerFunction", Type: token.IDENTIFIER},
 				{Line: 1, Lexeme: "(", Type: token.LEFT_PAREN},
 				{Line: 1, Lexeme: ")", Type: token.RIGHT_PAREN},
 				{Line: 1, Lexeme: "{", Type: token.LEFT_BRACE},
 
-				{Line: 2, Lexeme: "fun", Type: token.FUN},
+				{Line: 2, Lexeme: "fun", NewlineBefore: true, Type: token.FUN},
 				{Line: 2, Lexeme: "innerFunction", Type: token.IDENTIFIER},
 				{Line: 2, Lexeme: "(", Type: token.LEFT_PAREN},
 				{Line: 2, Lexeme: "str", Type: token.IDENTIFIER},
 				{Line: 2, Lexeme: ")", Type: token.RIGHT_PAREN},
 				{Line: 2, Lexeme: "{", Type: token.LEFT_BRACE},
 
-				{Line: 3, Lexeme: "print", Type: token.PRINT},
+				{Line: 3, Lexeme: "print", NewlineBefore: true, Type: token.PRINT},
 				{Line: 3, Lexeme: "str", Type: token.IDENTIFIER},
 				{Line: 3, Lexeme: ";", Type: token.SEMICOLON},
 
-				{Line: 4, Lexeme: "}", Type: token.RIGHT_BRACE},
+				{Line: 4, Lexeme: "}", NewlineBefore: true, Type: token.RIGHT_BRACE},
 
-				{Line: 6, Lexeme: "innerFunction", Type: token.IDENTIFIER},
+				{Line: 6, Lexeme: "innerFunction", NewlineBefore: true, Type: token.IDENTIFIER},
 				{Line: 6, Lexeme: "(", Type: token.LEFT_PAREN},
 				{Line: 6, Lexeme: "\"hello\"", Literal: "hello", Type: token.STRING},
 				{Line: 6, Lexeme: ")", Type: token.RIGHT_PAREN},
 				{Line: 6, Lexeme: ";", Type: token.SEMICOLON},
 
-				{Line: 7, Lexeme: "}", Type: token.RIGHT_BRACE},
+				{Line: 7, Lexeme: "}", NewlineBefore: true, Type: token.RIGHT_BRACE},
 
-				{Line: 8, Type: token.EOF},
+				{Line: 8, NewlineBefore: true, Type: token.EOF},
 			},
 		},
 		{
@@ -322,13 +376,13 @@ func TestScanTokens(t *testing.T) {
 				}
 			`,
 			expected: []*token.Token{
-				{Line: 1, Lexeme: "class", Type: token.CLASS},
+				{Line: 1, Lexeme: "class", NewlineBefore: true, Type: token.CLASS},
 				{Line: 1, Lexeme: "Brunch", Type: token.IDENTIFIER},
 				{Line: 1, Lexeme: "<", Type: token.LESS},
 				{Line: 1, Lexeme: "Breakfast", Type: token.IDENTIFIER},
 				{Line: 1, Lexeme: "{", Type: token.LEFT_BRACE},
 
-				{Line: 2, Lexeme: "init", Type: token.IDENTIFIER},
+				{Line: 2, Lexeme: "init", NewlineBefore: true, Type: token.IDENTIFIER},
 				{Line: 2, Lexeme: "(", Type: token.LEFT_PAREN},
 				{Line: 2, Lexeme: "meat", Type: token.IDENTIFIER},
 				{Line: 2, Lexeme: ",", Type: token.COMMA},
@@ -338,7 +392,7 @@ func TestScanTokens(t *testing.T) {
 				{Line: 2, Lexeme: ")", Type: token.RIGHT_PAREN},
 				{Line: 2, Lexeme: "{", Type: token.LEFT_BRACE},
 
-				{Line: 3, Lexeme: "super", Type: token.SUPER},
+				{Line: 3, Lexeme: "super", NewlineBefore: true, Type: token.SUPER},
 				{Line: 3, Lexeme: ".", Type: token.DOT},
 				{Line: 3, Lexeme: "init", Type: token.IDENTIFIER},
 				{Line: 3, Lexeme: "(", Type: token.LEFT_PAREN},
@@ -348,18 +402,18 @@ func TestScanTokens(t *testing.T) {
 				{Line: 3, Lexeme: ")", Type: token.RIGHT_PAREN},
 				{Line: 3, Lexeme: ";", Type: token.SEMICOLON},
 
-				{Line: 4, Lexeme: "this", Type: token.THIS},
+				{Line: 4, Lexeme: "this", NewlineBefore: true, Type: token.THIS},
 				{Line: 4, Lexeme: ".", Type: token.DOT},
 				{Line: 4, Lexeme: "drink", Type: token.IDENTIFIER},
 				{Line: 4, Lexeme: "=", Type: token.EQUAL},
 				{Line: 4, Lexeme: "drink", Type: token.IDENTIFIER},
 				{Line: 4, Lexeme: ";", Type: token.SEMICOLON},
 
-				{Line: 5, Lexeme: "}", Type: token.RIGHT_BRACE},
+				{Line: 5, Lexeme: "}", NewlineBefore: true, Type: token.RIGHT_BRACE},
 
-				{Line: 6, Lexeme: "}", Type: token.RIGHT_BRACE},
+				{Line: 6, Lexeme: "}", NewlineBefore: true, Type: token.RIGHT_BRACE},
 
-				{Line: 7, Type: token.EOF},
+				{Line: 7, NewlineBefore: true, Type: token.EOF},
 			},
 		},
 		{
@@ -4,7 +4,13 @@ type Token struct {
 	Lexeme  string
 	Line    int
 	Literal interface{}
-	Type    TokenType
+
+	// NewlineBefore is true if a line break separates this token from the
+	// one before it. The parser uses it to end statements at newlines when
+	// semicolons are optional.
+	NewlineBefore bool
+
+	Type TokenType
 }
 
 type TokenType int
@@ -15,6 +21,8 @@ const (
 	RIGHT_PAREN
 	LEFT_BRACE
 	RIGHT_BRACE
+	LEFT_BRACKET
+	RIGHT_BRACKET
 	COMMA
 	DOT
 	MINUS
@@ -23,15 +31,22 @@ const (
 	SLASH
 	STAR
 
-	// One or two character tokens
+	// One, two or three character tokens
 	BANG
 	BANG_EQUAL
+	DOT_DOT
+	DOT_DOT_EQUAL
 	EQUAL
 	EQUAL_EQUAL
+	EQUAL_GREATER
 	GREATER
 	GREATER_EQUAL
 	LESS
 	LESS_EQUAL
+	QUESTION
+	QUESTION_DOT
+	QUESTION_LEFT_BRACKET
+	QUESTION_QUESTION
 
 	// Literals
 	IDENTIFIER
@@ -40,12 +55,15 @@ const (
 
 	// Keywords
 	AND
+	ASSERT
+	CASE
 	CLASS
 	ELSE
 	FALSE
 	FUN
 	FOR
 	IF
+	MATCH
 	NIL
 	OR
 	PRINT
@@ -62,12 +80,15 @@ const (
 // Keywords maps of reserved keyword strings to their TokenType
 var Keywords = map[string]TokenType{
 	"and":    AND,
+	"assert": ASSERT,
+	"case":   CASE,
 	"class":  CLASS,
 	"else":   ELSE,
 	"false":  FALSE,
 	"for":    FOR,
 	"fun":    FUN,
 	"if":     IF,
+	"match":  MATCH,
 	"nil":    NIL,
 	"or":     OR,
 	"print":  PRINT,
@@ -0,0 +1,342 @@
+// Package types implements a static type checker for Lox programs.
+//
+// The checker walks the syntax tree before it is interpreted, infers the
+// type of every expression it can, and reports operations that would
+// certainly fail at runtime (e.g., `1 + "one"`). Anything it can't infer
+// is typed as Any and checked dynamically by the interpreter, as before.
+package types
+
+import (
+	"fmt"
+
+	"github.com/doeg/golox/golox/ast"
+	"github.com/doeg/golox/golox/loxerror"
+	"github.com/doeg/golox/golox/token"
+)
+
+// Type is the static type of a Lox expression.
+type Type int
+
+const (
+	// Any is the type of an expression that can only be known at runtime.
+	// It is compatible with every other type.
+	Any Type = iota
+	Nil
+	Bool
+	Number
+	String
+	Range
+)
+
+func (t Type) String() string {
+	switch t {
+	case Nil:
+		return "nil"
+	case Bool:
+		return "bool"
+	case Number:
+		return "number"
+	case String:
+		return "string"
+	case Range:
+		return "range"
+	default:
+		return "any"
+	}
+}
+
+// Checker infers expression types and records type errors.
+type Checker struct {
+	// errors accumulates type errors as the checker progresses
+	// so as many errors as possible can be collected in a single pass
+	errors []loxerror.LoxError
+
+	// warnings accumulates problems that don't stop a program from running,
+	// such as a match statement that may not handle every subject
+	warnings []loxerror.LoxError
+}
+
+func New() *Checker {
+	return &Checker{
+		errors:   make([]loxerror.LoxError, 0),
+		warnings: make([]loxerror.LoxError, 0),
+	}
+}
+
+// Check type checks the given statements, returning every type error found.
+func (c *Checker) Check(statements []ast.Stmt) []loxerror.LoxError {
+	for _, stmt := range statements {
+		c.checkStmt(stmt)
+	}
+
+	return c.errors
+}
+
+// Warnings returns the warnings recorded by Check.
+func (c *Checker) Warnings() []loxerror.LoxError {
+	return c.warnings
+}
+
+func (c *Checker) VisitAssertStmt(stmt *ast.AssertStmt) (any, error) {
+	c.checkExpr(stmt.Condition)
+
+	if stmt.Message != nil {
+		c.checkExpr(stmt.Message)
+	}
+
+	return nil, nil
+}
+
+func (c *Checker) VisitBinaryExpr(expr *ast.BinaryExpr) (any, error) {
+	left := c.checkExpr(expr.Left)
+	right := c.checkExpr(expr.Right)
+
+	switch expr.Operator.Type {
+	case token.BANG_EQUAL, token.EQUAL_EQUAL:
+		return Bool, nil
+	case token.GREATER, token.GREATER_EQUAL, token.LESS, token.LESS_EQUAL:
+		c.checkNumberOperands(expr.Operator, left, right)
+		return Bool, nil
+	case token.MINUS, token.SLASH, token.STAR:
+		c.checkNumberOperands(expr.Operator, left, right)
+		return Number, nil
+	case token.PLUS:
+		switch {
+		case left == Number && right == Number:
+			return Number, nil
+		case left == String && right == String:
+			return String, nil
+		case left == Any && isAddable(right), right == Any && isAddable(left):
+			return Any, nil
+		}
+
+		c.recordError(expr.Operator, fmt.Sprintf("operands of '+' must be two numbers or two strings, got %s and %s", left, right))
+		return Any, nil
+	}
+
+	return Any, nil
+}
+
+func (c *Checker) VisitExpressionStmt(stmt *ast.ExpressionStmt) (any, error) {
+	c.checkExpr(stmt.Expression)
+	return nil, nil
+}
+
+func (c *Checker) VisitGroupingExpr(expr *ast.GroupingExpr) (any, error) {
+	return c.checkExpr(expr.Expression), nil
+}
+
+func (c *Checker) VisitIndexExpr(expr *ast.IndexExpr) (any, error) {
+	t, _ := c.checkIndex(expr)
+	return t, nil
+}
+
+func (c *Checker) VisitLiteralExpr(expr *ast.LiteralExpr) (any, error) {
+	return typeOf(expr.Value), nil
+}
+
+func (c *Checker) VisitLogicalExpr(expr *ast.LogicalExpr) (any, error) {
+	left := c.checkExpr(expr.Left)
+
+	switch expr.Operator.Type {
+	case token.QUESTION_QUESTION:
+		// The right side of `??` never runs when the left side can't be
+		// nil, so it can't fail either.
+		if left != Nil && left != Any {
+			return left, nil
+		}
+
+		right := c.checkExpr(expr.Right)
+		if left == Nil {
+			return right, nil
+		}
+	}
+
+	return Any, nil
+}
+
+// VisitMatchStmt records a warning for each pattern that can never match the
+// subject's type, and another unless the patterns are exhaustive. Neither
+// stops the program from running, since a case that never matches is only
+// dead code. Without
+// a wildcard, only `true` and `false` over a bool, `nil` over nil and `..`
+// over a number cover every possible subject.
+func (c *Checker) VisitMatchStmt(stmt *ast.MatchStmt) (any, error) {
+	subject := c.checkExpr(stmt.Subject)
+
+	exhaustive := false
+	bools := make(map[bool]bool)
+
+	for _, pattern := range stmt.Patterns {
+		switch p := pattern.(type) {
+		case *ast.LiteralPattern:
+			t := typeOf(p.Value)
+			if subject != Any && t != subject {
+				c.recordWarning(p.Token, fmt.Sprintf("pattern of type %s can never match a subject of type %s", t, subject))
+			}
+
+			if b, ok := p.Value.(bool); ok && subject == Bool {
+				bools[b] = true
+			}
+
+			if t == Nil && subject == Nil {
+				exhaustive = true
+			}
+		case *ast.RangePattern:
+			if subject != Any && subject != Number {
+				c.recordWarning(p.Operator, fmt.Sprintf("range pattern can never match a subject of type %s", subject))
+			}
+
+			if p.Start == nil && p.End == nil && subject == Number {
+				exhaustive = true
+			}
+		case *ast.WildcardPattern:
+			exhaustive = true
+		}
+	}
+
+	if bools[true] && bools[false] {
+		exhaustive = true
+	}
+
+	if !exhaustive {
+		c.recordWarning(stmt.Keyword, fmt.Sprintf("match over %s is not exhaustive; add a '_' case", subject))
+	}
+
+	for _, body := range stmt.Bodies {
+		c.checkStmt(body)
+	}
+
+	return nil, nil
+}
+
+func (c *Checker) VisitPrintStmt(stmt *ast.PrintStmt) (any, error) {
+	c.checkExpr(stmt.Expression)
+	return nil, nil
+}
+
+func (c *Checker) VisitRangeExpr(expr *ast.RangeExpr) (any, error) {
+	for _, bound := range []ast.Expr{expr.Start, expr.End} {
+		if bound == nil {
+			continue
+		}
+
+		if t := c.checkExpr(bound); t != Number && t != Any {
+			c.recordError(expr.Operator, fmt.Sprintf("range bounds must be numbers, got %s", t))
+		}
+	}
+
+	return Range, nil
+}
+
+func (c *Checker) VisitUnaryExpr(expr *ast.UnaryExpr) (any, error) {
+	right := c.checkExpr(expr.Right)
+
+	switch expr.Operator.Type {
+	case token.MINUS:
+		if right != Number && right != Any {
+			c.recordError(expr.Operator, fmt.Sprintf("operand of '-' must be a number, got %s", right))
+		}
+		return Number, nil
+	case token.BANG:
+		return Bool, nil
+	}
+
+	return Any, nil
+}
+
+// checkExpr returns the inferred type of the given expression.
+func (c *Checker) checkExpr(expr ast.Expr) Type {
+	t, err := expr.Accept(c)
+	if err != nil {
+		return Any
+	}
+
+	return t.(Type)
+}
+
+// checkIndex returns the inferred type of an index expression, and whether
+// it certainly short-circuits on a null-safe index of nil. Like the
+// interpreter, it then skips the index and the rest of the chain, since they
+// never run and so can't fail.
+func (c *Checker) checkIndex(expr *ast.IndexExpr) (Type, bool) {
+	var object Type
+
+	if inner, ok := expr.Object.(*ast.IndexExpr); ok {
+		t, isShort := c.checkIndex(inner)
+		if isShort {
+			return t, true
+		}
+		object = t
+	} else {
+		object = c.checkExpr(expr.Object)
+	}
+
+	if object == Nil && expr.Bracket.Type == token.QUESTION_LEFT_BRACKET {
+		return Any, true
+	}
+
+	index := c.checkExpr(expr.Index)
+
+	if object != String && object != Any {
+		c.recordError(expr.Bracket, fmt.Sprintf("only strings can be indexed, got %s", object))
+	}
+
+	if index != Number && index != Range && index != Any {
+		c.recordError(expr.Bracket, fmt.Sprintf("index must be a number or a range, got %s", index))
+	}
+
+	if object == String {
+		return String, false
+	}
+
+	return Any, false
+}
+
+// checkNumberOperands records an error unless both operands may be numbers.
+func (c *Checker) checkNumberOperands(operator *token.Token, left, right Type) {
+	if (left == Number || left == Any) && (right == Number || right == Any) {
+		return
+	}
+
+	c.recordError(operator, fmt.Sprintf("operands of '%s' must be numbers, got %s and %s", operator.Lexeme, left, right))
+}
+
+func (c *Checker) checkStmt(stmt ast.Stmt) {
+	stmt.Accept(c)
+}
+
+func (c *Checker) recordError(operator *token.Token, message string) {
+	c.errors = append(c.errors, loxerror.LoxError{
+		Line:    operator.Line,
+		Message: message,
+	})
+}
+
+func (c *Checker) recordWarning(tok *token.Token, message string) {
+	c.warnings = append(c.warnings, loxerror.LoxError{
+		Line:    tok.Line,
+		Message: message,
+	})
+}
+
+// isAddable returns true if a value of the given type may be an operand of '+'.
+func isAddable(t Type) bool {
+	return t == Number || t == String || t == Any
+}
+
+// typeOf returns the type of a literal value.
+func typeOf(val any) Type {
+	switch val.(type) {
+	case nil:
+		return Nil
+	case bool:
+		return Bool
+	case float64:
+		return Number
+	case string:
+		return String
+	default:
+		return Any
+	}
+}
@@ -0,0 +1,205 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/doeg/golox/golox/loxerror"
+	"github.com/doeg/golox/golox/parser"
+	"github.com/doeg/golox/golox/scanner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferType(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Type
+	}{
+		{input: "nil", expected: Nil},
+		{input: "true", expected: Bool},
+		{input: "1", expected: Number},
+		{input: "\"hello\"", expected: String},
+		{input: "-1", expected: Number},
+		{input: "!1", expected: Bool},
+		{input: "(1 + 2) * 3", expected: Number},
+		{input: "\"a\" + \"b\"", expected: String},
+		{input: "1 < 2", expected: Bool},
+		{input: "1 == \"a\"", expected: Bool},
+		{input: "1 + \"a\"", expected: Any},
+		{input: "1..3", expected: Range},
+		{input: "nil ?? 1", expected: Number},
+		{input: "\"a\" ?? 1", expected: String},
+		{input: "nil?[0][1]", expected: Any},
+		{input: "\"hello\"[1..]", expected: String},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			s := scanner.New([]byte(tt.input))
+			tokens, errs := s.ScanTokens()
+			require.Empty(t, errs)
+
+			p := parser.New(tokens)
+			expr, err := p.ParseExpression()
+			require.Nil(t, err)
+
+			c := New()
+			assert.Equal(t, tt.expected, c.checkExpr(expr))
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedErrors   []loxerror.LoxError
+		expectedWarnings []loxerror.LoxError
+	}{
+		{
+			input: "print 1 + 2; print \"a\" + \"b\"; 1 == nil;",
+		},
+		{
+			input: "print 1 + \"a\";",
+			expectedErrors: []loxerror.LoxError{
+				{Line: 0, Message: "operands of '+' must be two numbers or two strings, got number and string"},
+			},
+		},
+		{
+			input: "print -\"a\";",
+			expectedErrors: []loxerror.LoxError{
+				{Line: 0, Message: "operand of '-' must be a number, got string"},
+			},
+		},
+		{
+			input: "print 1;\nprint true < 2;\nprint nil * \"a\";",
+			expectedErrors: []loxerror.LoxError{
+				{Line: 1, Message: "operands of '<' must be numbers, got bool and number"},
+				{Line: 2, Message: "operands of '*' must be numbers, got nil and string"},
+			},
+		},
+		{
+			input: "print (1 + \"a\") + 1;",
+			expectedErrors: []loxerror.LoxError{
+				{Line: 0, Message: "operands of '+' must be two numbers or two strings, got number and string"},
+			},
+		},
+		{
+			input: "print \"a\"[1..true]; print 1[\"a\"];",
+			expectedErrors: []loxerror.LoxError{
+				{Line: 0, Message: "range bounds must be numbers, got bool"},
+				{Line: 0, Message: "only strings can be indexed, got number"},
+				{Line: 0, Message: "index must be a number or a range, got string"},
+			},
+		},
+		{
+			input: "assert 1 < \"a\", -true;",
+			expectedErrors: []loxerror.LoxError{
+				{Line: 0, Message: "operands of '<' must be numbers, got number and string"},
+				{Line: 0, Message: "operand of '-' must be a number, got bool"},
+			},
+		},
+		{
+			input: "match (1 - true) { case 1 => print \"a\" / 2; }",
+			expectedErrors: []loxerror.LoxError{
+				{Line: 0, Message: "operands of '-' must be numbers, got number and bool"},
+				{Line: 0, Message: "operands of '/' must be numbers, got string and number"},
+			},
+			expectedWarnings: []loxerror.LoxError{
+				{Line: 0, Message: "match over number is not exhaustive; add a '_' case"},
+			},
+		},
+		{
+			input: "print \"x\" ?? (1 + \"a\");",
+		},
+		{
+			input: "print nil?[1 - \"a\"]; print nil?[0][true];",
+		},
+		{
+			input: "print \"a\"?[1 - \"a\"];",
+			expectedErrors: []loxerror.LoxError{
+				{Line: 0, Message: "operands of '-' must be numbers, got number and string"},
+			},
+		},
+		{
+			input: "print nil ?? (1 + \"a\"); print nil?[0] ?? -\"b\";",
+			expectedErrors: []loxerror.LoxError{
+				{Line: 0, Message: "operands of '+' must be two numbers or two strings, got number and string"},
+				{Line: 0, Message: "operand of '-' must be a number, got string"},
+			},
+		},
+		{
+			input: "match (\"a\"[0]) { case 1 => print 1; }",
+			expectedWarnings: []loxerror.LoxError{
+				{Line: 0, Message: "pattern of type number can never match a subject of type string"},
+				{Line: 0, Message: "match over string is not exhaustive; add a '_' case"},
+			},
+		},
+		{
+			input: "match (1 < 2) { case true => print 1; case false => print 2; }",
+		},
+		{
+			input: "match (1) { case ..0 => print 1; case 0.. => print 2; case .. => print 3; }",
+		},
+		{
+			input: "match (\"a\") { case \"a\" => print 1; case _ => print 2; }",
+		},
+		{
+			input: "match (1 < 2) {\ncase true => print 1;\n}",
+			expectedWarnings: []loxerror.LoxError{
+				{Line: 0, Message: "match over bool is not exhaustive; add a '_' case"},
+			},
+		},
+		{
+			input: "match (\"a\"[0]) { case \"a\" => print 1; case \"b\" => print 2; }",
+			expectedWarnings: []loxerror.LoxError{
+				{Line: 0, Message: "match over string is not exhaustive; add a '_' case"},
+			},
+		},
+		{
+			input: "match (1) {\ncase \"a\" => print 1;\ncase 1..2 => print 2;\ncase _ => print 3;\n}",
+			expectedWarnings: []loxerror.LoxError{
+				{Line: 1, Message: "pattern of type string can never match a subject of type number"},
+			},
+		},
+		{
+			input: "match (\"a\") {\ncase nil => print 1;\ncase 1.. => print 2;\ncase _ => print 3;\n}",
+			expectedWarnings: []loxerror.LoxError{
+				{Line: 1, Message: "pattern of type nil can never match a subject of type string"},
+				{Line: 2, Message: "range pattern can never match a subject of type string"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			s := scanner.New([]byte(tt.input))
+			tokens, errs := s.ScanTokens()
+			require.Empty(t, errs)
+
+			p := parser.New(tokens)
+			stmts, err := p.Parse()
+			require.Nil(t, err)
+
+			c := New()
+			errs = c.Check(stmts)
+			if len(tt.expectedErrors) > 0 {
+				assert.EqualValues(t, tt.expectedErrors, errs)
+			} else {
+				assert.Empty(t, errs, fmt.Sprintf("%+v", errs))
+			}
+
+			if len(tt.expectedWarnings) > 0 {
+				assert.EqualValues(t, tt.expectedWarnings, c.Warnings())
+			} else {
+				assert.Empty(t, c.Warnings(), fmt.Sprintf("%+v", c.Warnings()))
+			}
+		})
+	}
+}
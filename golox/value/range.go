@@ -0,0 +1,114 @@
+package value
+
+import (
+	"math"
+	"strings"
+)
+
+// Range is the runtime value of a range expression such as `1..3` or `..=5`.
+// A range without a start or end bound is open on that side.
+type Range struct {
+	Start    float64
+	End      float64
+	HasStart bool
+	HasEnd   bool
+
+	// Inclusive is true for `..=` ranges, which include their end bound.
+	Inclusive bool
+}
+
+// Bounds returns the half-open interval [start, end) that the range selects
+// from a sequence of the given length.
+//
+// Negative bounds count back from the end of the sequence, so -1 is the last
+// element. An open start is 0 and an open end is the length of the sequence.
+// Bounds that fall outside the sequence are clamped to it, and a range whose
+// start comes after its end selects nothing; slicing with a range never fails.
+func (r Range) Bounds(length int) (int, int) {
+	// Resolve the bounds in float64 and only convert them to int once they
+	// are clamped, since converting a float64 outside int's range is
+	// undefined in Go.
+	start, end := 0.0, float64(length)
+
+	if r.HasStart {
+		start = r.Start
+		if start < 0 {
+			start += float64(length)
+		}
+	}
+
+	if r.HasEnd {
+		end = r.End
+		if end < 0 {
+			end += float64(length)
+		}
+		if r.Inclusive {
+			end++
+		}
+	}
+
+	s := clamp(start, length)
+	e := clamp(end, length)
+	if s > e {
+		s = e
+	}
+
+	return s, e
+}
+
+func (r Range) String() string {
+	var b strings.Builder
+
+	if r.HasStart {
+		b.WriteString(Number(r.Start).String())
+	}
+
+	if r.Inclusive {
+		b.WriteString("..=")
+	} else {
+		b.WriteString("..")
+	}
+
+	if r.HasEnd {
+		b.WriteString(Number(r.End).String())
+	}
+
+	return b.String()
+}
+
+func (r Range) TypeName() string {
+	return "range"
+}
+
+func (r Range) Hash() uint64 {
+	var h uint64 = 17
+
+	for _, f := range []float64{r.Start, r.End} {
+		// 0 and -0 are equal, so they must hash the same.
+		if f == 0 {
+			f = 0
+		}
+		h = h*31 + math.Float64bits(f)
+	}
+
+	for _, b := range []bool{r.HasStart, r.HasEnd, r.Inclusive} {
+		h *= 31
+		if b {
+			h++
+		}
+	}
+
+	return h
+}
+
+// clamp converts f to an int within [0, length].
+func clamp(f float64, length int) int {
+	switch {
+	case f <= 0:
+		return 0
+	case f >= float64(length):
+		return length
+	default:
+		return int(f)
+	}
+}
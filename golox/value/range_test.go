@@ -0,0 +1,141 @@
+package value
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeBounds(t *testing.T) {
+	tests := []struct {
+		testName      string
+		input         Range
+		length        int
+		expectedStart int
+		expectedEnd   int
+	}{
+		{
+			testName:      "1..3",
+			input:         Range{Start: 1, End: 3, HasStart: true, HasEnd: true},
+			length:        5,
+			expectedStart: 1,
+			expectedEnd:   3,
+		},
+		{
+			testName:      "1..=3",
+			input:         Range{Start: 1, End: 3, HasStart: true, HasEnd: true, Inclusive: true},
+			length:        5,
+			expectedStart: 1,
+			expectedEnd:   4,
+		},
+		{
+			testName:      "..",
+			input:         Range{},
+			length:        5,
+			expectedStart: 0,
+			expectedEnd:   5,
+		},
+		{
+			testName:      "-2..",
+			input:         Range{Start: -2, HasStart: true},
+			length:        5,
+			expectedStart: 3,
+			expectedEnd:   5,
+		},
+		{
+			testName:      "..=-1",
+			input:         Range{End: -1, HasEnd: true, Inclusive: true},
+			length:        5,
+			expectedStart: 0,
+			expectedEnd:   5,
+		},
+		{
+			testName:      "clamps out of range bounds",
+			input:         Range{Start: -10, End: 10, HasStart: true, HasEnd: true},
+			length:        5,
+			expectedStart: 0,
+			expectedEnd:   5,
+		},
+		{
+			testName:      "clamps huge start",
+			input:         Range{Start: 1e22, HasStart: true},
+			length:        5,
+			expectedStart: 5,
+			expectedEnd:   5,
+		},
+		{
+			testName:      "clamps huge inclusive end",
+			input:         Range{End: 1e22, HasEnd: true, Inclusive: true},
+			length:        5,
+			expectedStart: 0,
+			expectedEnd:   5,
+		},
+		{
+			testName:      "clamps huge negative bounds",
+			input:         Range{Start: -1e22, End: -1e22, HasStart: true, HasEnd: true},
+			length:        5,
+			expectedStart: 0,
+			expectedEnd:   0,
+		},
+		{
+			testName:      "clamps infinite end",
+			input:         Range{Start: 1, End: math.Inf(1), HasStart: true, HasEnd: true},
+			length:        5,
+			expectedStart: 1,
+			expectedEnd:   5,
+		},
+		{
+			testName:      "..=-100",
+			input:         Range{End: -100, HasEnd: true, Inclusive: true},
+			length:        3,
+			expectedStart: 0,
+			expectedEnd:   0,
+		},
+		{
+			testName:      "-100..=-100",
+			input:         Range{Start: -100, End: -100, HasStart: true, HasEnd: true, Inclusive: true},
+			length:        3,
+			expectedStart: 0,
+			expectedEnd:   0,
+		},
+		{
+			testName:      "..=-3",
+			input:         Range{End: -3, HasEnd: true, Inclusive: true},
+			length:        3,
+			expectedStart: 0,
+			expectedEnd:   1,
+		},
+		{
+			testName:      "..=-4",
+			input:         Range{End: -4, HasEnd: true, Inclusive: true},
+			length:        3,
+			expectedStart: 0,
+			expectedEnd:   0,
+		},
+		{
+			testName:      "start after end is empty",
+			input:         Range{Start: 4, End: 1, HasStart: true, HasEnd: true},
+			length:        5,
+			expectedStart: 1,
+			expectedEnd:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.testName, func(t *testing.T) {
+			t.Parallel()
+
+			start, end := tt.input.Bounds(tt.length)
+			assert.Equal(t, tt.expectedStart, start)
+			assert.Equal(t, tt.expectedEnd, end)
+		})
+	}
+}
+
+func TestRangeString(t *testing.T) {
+	assert.Equal(t, "1..3", Range{Start: 1, End: 3, HasStart: true, HasEnd: true}.String())
+	assert.Equal(t, "..=-1", Range{End: -1, HasEnd: true, Inclusive: true}.String())
+	assert.Equal(t, "2..", Range{Start: 2, HasStart: true}.String())
+}
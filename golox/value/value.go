@@ -0,0 +1,174 @@
+// Package value defines the runtime representation of Lox values.
+package value
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Value is a Lox runtime value.
+//
+// Values are compared with Equal. Unless a value implements Equaler, it is
+// compared with Go's == operator, so values with identity, such as objects
+// and functions, should be pointers so that they compare by identity.
+type Value interface {
+	// String returns the value formatted as Lox's print statement shows it.
+	String() string
+
+	// TypeName returns the name of the value's type, e.g. "number".
+	TypeName() string
+}
+
+// Equaler is implemented by values that define their own equality, such as
+// instances that compare by their fields. Equal must be symmetric.
+type Equaler interface {
+	Equal(other Value) bool
+}
+
+// Hasher is implemented by values that can be hashed other than the
+// built-in primitives. Hash must return the same result for any two values
+// that are Equal.
+type Hasher interface {
+	Hash() uint64
+}
+
+// Nil is Lox's nil value.
+type Nil struct{}
+
+func (n Nil) String() string {
+	return "nil"
+}
+
+func (n Nil) TypeName() string {
+	return "nil"
+}
+
+type Bool bool
+
+func (b Bool) String() string {
+	return strconv.FormatBool(bool(b))
+}
+
+func (b Bool) TypeName() string {
+	return "bool"
+}
+
+type Number float64
+
+// String formats the number without a fractional part when it's a whole
+// number, so `print 3` shows "3" rather than "3.0" or "3e+00".
+func (n Number) String() string {
+	f := float64(n)
+
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.Abs(f) < 1e21:
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
+
+func (n Number) TypeName() string {
+	return "number"
+}
+
+type String string
+
+func (s String) String() string {
+	return string(s)
+}
+
+func (s String) TypeName() string {
+	return "string"
+}
+
+// Of converts a Go value, such as a literal produced by the scanner,
+// to a Value.
+func Of(v any) (Value, error) {
+	switch v := v.(type) {
+	case nil:
+		return Nil{}, nil
+	case bool:
+		return Bool(v), nil
+	case float64:
+		return Number(v), nil
+	case string:
+		return String(v), nil
+	case Value:
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("unsupported value type %T", v)
+}
+
+// Equal returns true if the two values are equal. Values of different types
+// are never equal, and NaN is not equal to itself.
+//
+// If either value is an Equaler, its Equal method decides. Otherwise the
+// values are compared with ==, except that values whose type isn't
+// comparable (and so would make == panic) are never equal.
+func Equal(a, b Value) bool {
+	if e, ok := a.(Equaler); ok {
+		return e.Equal(b)
+	}
+
+	if e, ok := b.(Equaler); ok {
+		return e.Equal(a)
+	}
+
+	ta := reflect.TypeOf(a)
+	if ta == nil || ta != reflect.TypeOf(b) || !ta.Comparable() {
+		return a == nil && b == nil
+	}
+
+	return a == b
+}
+
+// Truthy returns false for nil and false, and true for everything else.
+func Truthy(v Value) bool {
+	switch v := v.(type) {
+	case Nil:
+		return false
+	case Bool:
+		return bool(v)
+	default:
+		return true
+	}
+}
+
+// Hash returns a hash of the value, for use as a map key. It returns an
+// error for values that can't be hashed.
+func Hash(v Value) (uint64, error) {
+	switch v := v.(type) {
+	case Nil:
+		return 0, nil
+	case Bool:
+		if v {
+			return 1, nil
+		}
+		return 2, nil
+	case Number:
+		// 0 and -0 are equal, so they must hash the same.
+		if v == 0 {
+			v = 0
+		}
+		return math.Float64bits(float64(v)), nil
+	case String:
+		h := fnv.New64a()
+		h.Write([]byte(v))
+		return h.Sum64(), nil
+	case Hasher:
+		return v.Hash(), nil
+	}
+
+	return 0, fmt.Errorf("unhashable type: %s", v.TypeName())
+}
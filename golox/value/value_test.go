@@ -0,0 +1,251 @@
+package value
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// point compares and hashes by its fields, like a data class instance.
+type point struct {
+	x, y float64
+}
+
+func (p *point) String() string {
+	return fmt.Sprintf("point(%v, %v)", p.x, p.y)
+}
+
+func (p *point) TypeName() string {
+	return "point"
+}
+
+func (p *point) Equal(other Value) bool {
+	o, ok := other.(*point)
+	return ok && p.x == o.x && p.y == o.y
+}
+
+func (p *point) Hash() uint64 {
+	return math.Float64bits(p.x)*31 + math.Float64bits(p.y)
+}
+
+// list can't be compared with ==, which would panic.
+type list []Value
+
+func (l list) String() string {
+	return fmt.Sprint([]Value(l))
+}
+
+func (l list) TypeName() string {
+	return "list"
+}
+
+func TestTruthy(t *testing.T) {
+	tests := []struct {
+		input    Value
+		expected bool
+	}{
+		{
+			input:    Bool(false),
+			expected: false,
+		},
+		{
+			input:    Nil{},
+			expected: false,
+		},
+		{
+			input:    Bool(true),
+			expected: true,
+		},
+		{
+			input:    String("hello"),
+			expected: true,
+		},
+		{
+			input:    Number(0),
+			expected: true,
+		},
+		{
+			input:    Number(1),
+			expected: true,
+		},
+		{
+			input:    Number(-1),
+			expected: true,
+		},
+		{
+			input:    String(""),
+			expected: true,
+		},
+		{
+			input:    Range{},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(fmt.Sprintf("%s %s", tt.input.TypeName(), tt.input), func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, Truthy(tt.input))
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		input    Value
+		expected string
+	}{
+		{input: Nil{}, expected: "nil"},
+		{input: Bool(true), expected: "true"},
+		{input: Bool(false), expected: "false"},
+		{input: Number(3), expected: "3"},
+		{input: Number(-3), expected: "-3"},
+		{input: Number(0.5), expected: "0.5"},
+		{input: Number(1.0 / 3), expected: "0.3333333333333333"},
+		{input: Number(1000000), expected: "1000000"},
+		{input: Number(1e21), expected: "1e+21"},
+		{input: Number(math.Inf(1)), expected: "inf"},
+		{input: Number(math.Inf(-1)), expected: "-inf"},
+		{input: Number(math.NaN()), expected: "nan"},
+		{input: String("hello"), expected: "hello"},
+		{input: String(""), expected: ""},
+		{input: Range{Start: 1, End: 3, HasStart: true, HasEnd: true}, expected: "1..3"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.expected, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, tt.input.String())
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a        Value
+		b        Value
+		expected bool
+	}{
+		{a: Nil{}, b: Nil{}, expected: true},
+		{a: Bool(true), b: Bool(true), expected: true},
+		{a: Bool(true), b: Bool(false), expected: false},
+		{a: Number(1), b: Number(1), expected: true},
+		{a: Number(0), b: Number(math.Copysign(0, -1)), expected: true},
+		{a: Number(math.NaN()), b: Number(math.NaN()), expected: false},
+		{a: String("a"), b: String("a"), expected: true},
+		{a: String("a"), b: String("b"), expected: false},
+		{a: Number(0), b: Bool(false), expected: false},
+		{a: Nil{}, b: Bool(false), expected: false},
+		{a: String("1"), b: Number(1), expected: false},
+		{
+			a:        Range{Start: 1, End: 3, HasStart: true, HasEnd: true},
+			b:        Range{Start: 1, End: 3, HasStart: true, HasEnd: true},
+			expected: true,
+		},
+		{
+			a:        Range{Start: 1, End: 3, HasStart: true, HasEnd: true},
+			b:        Range{Start: 1, End: 3, HasStart: true, HasEnd: true, Inclusive: true},
+			expected: false,
+		},
+		{a: &point{x: 1, y: 2}, b: &point{x: 1, y: 2}, expected: true},
+		{a: &point{x: 1, y: 2}, b: &point{x: 2, y: 1}, expected: false},
+		{a: &point{x: 0, y: 0}, b: Nil{}, expected: false},
+		{a: list{Number(1)}, b: list{Number(1)}, expected: false},
+		{a: list{}, b: Nil{}, expected: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(fmt.Sprintf("%s == %s", tt.a, tt.b), func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, Equal(tt.a, tt.b))
+			assert.Equal(t, tt.expected, Equal(tt.b, tt.a))
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	values := []Value{
+		Nil{},
+		Bool(true),
+		Bool(false),
+		Number(0),
+		Number(math.Copysign(0, -1)),
+		Number(1.5),
+		String(""),
+		String("hello"),
+		Range{Start: 1, End: 3, HasStart: true, HasEnd: true},
+		Range{Start: math.Copysign(0, -1), HasStart: true},
+		&point{x: 1, y: 2},
+		&point{x: 1, y: 2},
+	}
+
+	// Equal values must hash the same
+	for _, a := range values {
+		for _, b := range values {
+			if !Equal(a, b) {
+				continue
+			}
+
+			ha, err := Hash(a)
+			require.Nil(t, err)
+
+			hb, err := Hash(b)
+			require.Nil(t, err)
+
+			assert.Equal(t, ha, hb, "%s and %s", a, b)
+		}
+	}
+
+	ha, err := Hash(String("a"))
+	require.Nil(t, err)
+	hb, err := Hash(String("b"))
+	require.Nil(t, err)
+	assert.NotEqual(t, ha, hb)
+}
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		input         any
+		expected      Value
+		expectedError error
+	}{
+		{input: nil, expected: Nil{}},
+		{input: true, expected: Bool(true)},
+		{input: float64(1), expected: Number(1)},
+		{input: "hello", expected: String("hello")},
+		{input: Range{}, expected: Range{}},
+		{input: 1, expectedError: fmt.Errorf("unsupported value type int")},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(fmt.Sprintf("%T", tt.input), func(t *testing.T) {
+			t.Parallel()
+
+			result, err := Of(tt.input)
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, err)
+			} else {
+				require.Nil(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTypeName(t *testing.T) {
+	assert.Equal(t, "nil", Nil{}.TypeName())
+	assert.Equal(t, "bool", Bool(true).TypeName())
+	assert.Equal(t, "number", Number(1).TypeName())
+	assert.Equal(t, "string", String("").TypeName())
+	assert.Equal(t, "range", Range{}.TypeName())
+}